@@ -0,0 +1,235 @@
+// Package awssm provides a [secretly.GetSecretFunc] for AWS Secrets Manager,
+// speaking its JSON API over HTTP, signed with AWS Signature Version 4.
+package awssm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	service = "secretsmanager"
+
+	// Error types reported by Secrets Manager for missing secrets and versions.
+	errTypeResourceNotFound = "ResourceNotFoundException"
+)
+
+// regexVersionID matches version IDs generated by Secrets Manager, which are UUIDs.
+var regexVersionID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var (
+	ErrMissingRegion      = errors.New("awssm: missing region")
+	ErrMissingCredentials = errors.New("awssm: missing credentials")
+)
+
+type (
+	// Config configures the AWS Secrets Manager provider.
+	Config struct {
+		// Region is the AWS region, e.g. "us-east-1".
+		Region string
+
+		// Endpoint is Secrets Manager's endpoint.
+		// Defaults to "https://secretsmanager.<Region>.amazonaws.com".
+		Endpoint string
+
+		// Credentials are used to sign requests.
+		// See [CredentialsFromEnv].
+		Credentials Credentials
+
+		// Client is the HTTP client used to make requests.
+		// Defaults to [http.DefaultClient].
+		Client *http.Client
+
+		// now returns the current time, used to sign requests.
+		// Defaults to [time.Now].
+		now func() time.Time
+	}
+
+	// Credentials are the AWS credentials used to sign requests.
+	Credentials struct {
+		AccessKeyID     string
+		SecretAccessKey string
+		SessionToken    string
+	}
+
+	// provider gets secrets from Secrets Manager.
+	provider struct {
+		cfg Config
+	}
+)
+
+// CredentialsFromEnv returns the credentials set by the standard AWS environment
+// variables: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func CredentialsFromEnv() Credentials {
+	return Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets
+// from AWS Secrets Manager. Secret names are the secret's name or ARN.
+//
+// Versions that are UUIDs are treated as version IDs,
+// otherwise versions are treated as version stages, e.g. "AWSCURRENT" or "AWSPREVIOUS".
+// [secretly.DefaultVersion] translates to the "AWSCURRENT" stage.
+//
+// Secrets stored as SecretString are returned as is,
+// and secrets stored as SecretBinary are returned decoded.
+//
+// Missing secrets and versions are reported with errors wrapping
+// [secretly.ErrSecretNotFound]. Other unexpected responses
+// are reported with a [secretly.HTTPError].
+func Provider(cfg Config) (secretly.GetSecretFunc, error) {
+	if cfg.Region == "" {
+		return nil, ErrMissingRegion
+	}
+
+	if cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://" + service + "." + cfg.Region + ".amazonaws.com"
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	if cfg.now == nil {
+		cfg.now = time.Now
+	}
+
+	p := &provider{cfg: cfg}
+
+	return p.getSecret, nil
+}
+
+// getSecret gets the secret's value, implementing [secretly.GetSecretFunc].
+func (p *provider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	input := struct {
+		SecretID     string `json:"SecretId"`
+		VersionID    string `json:"VersionId,omitempty"`
+		VersionStage string `json:"VersionStage,omitempty"`
+	}{
+		SecretID: name,
+	}
+
+	switch {
+	case version == secretly.DefaultVersion:
+	case regexVersionID.MatchString(version):
+		input.VersionID = version
+	default:
+		input.VersionStage = version
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := p.do(ctx, "GetSecretValue", body)
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %q version %q: %w", name, version, err)
+	}
+
+	var output struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+
+	err = json.Unmarshal(b, &output)
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret %q version %q: %w", name, version, err)
+	}
+
+	if output.SecretString != nil {
+		return []byte(*output.SecretString), nil
+	}
+
+	return output.SecretBinary, nil
+}
+
+// do calls the Secrets Manager action, returning the response body.
+// ResourceNotFoundException responses are reported with an error wrapping
+// [secretly.ErrSecretNotFound], and other non-2xx responses with a [secretly.HTTPError].
+func (p *provider) do(ctx context.Context, action string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", service+"."+action)
+
+	sign(req, body, p.cfg.Credentials, p.cfg.Region, service, p.cfg.now())
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return b, nil
+	}
+
+	errType, message := errorTypeAndMessage(b)
+
+	httpErr := secretly.HTTPError{StatusCode: resp.StatusCode, Message: message}
+	if errType != "" {
+		httpErr.Message = errType + ": " + message
+	}
+
+	if errType == errTypeResourceNotFound {
+		return nil, fmt.Errorf("%w: %w", secretly.ErrSecretNotFound, httpErr)
+	}
+
+	return nil, httpErr
+}
+
+// errorTypeAndMessage returns the error type and message
+// from Secrets Manager's error response body, b.
+func errorTypeAndMessage(b []byte) (errType, message string) {
+	var resp struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return "", ""
+	}
+
+	// The type may be prefixed with the service's namespace,
+	// e.g. "com.amazonaws.secretsmanager#ResourceNotFoundException"
+	errType = resp.Type
+	if i := strings.LastIndex(errType, "#"); i >= 0 {
+		errType = errType[i+1:]
+	}
+
+	message = resp.Message
+	if message == "" {
+		message = resp.MessageUpper
+	}
+
+	return errType, message
+}
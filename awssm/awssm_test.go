@@ -0,0 +1,221 @@
+package awssm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	testRegion    = "us-east-1"
+	testVersionID = "01234567-89ab-cdef-0123-456789abcdef"
+)
+
+var (
+	testCreds = Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		SessionToken:    "session-token",
+	}
+	testNow = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+// newTestServer returns a stand-in for Secrets Manager's GetSecretValue action,
+// verifying each request's signature.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if !validSignature(r, body) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"__type": "InvalidSignatureException", "message": "invalid signature"}`))
+			return
+		}
+
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "UnknownOperationException"}`))
+			return
+		}
+
+		var input map[string]string
+		_ = json.Unmarshal(body, &input)
+
+		notFound := func() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "com.amazonaws.secretsmanager#ResourceNotFoundException", "message": "not found"}`))
+		}
+
+		switch {
+		case input["SecretId"] == "throttled":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "ThrottlingException", "message": "rate exceeded"}`))
+		case input["SecretId"] == "binary":
+			_, _ = w.Write([]byte(`{"SecretBinary": "AP8s"}`))
+		case input["SecretId"] != "db-credentials":
+			notFound()
+		case input["VersionId"] == testVersionID:
+			_, _ = w.Write([]byte(`{"SecretString": "version id secret"}`))
+		case input["VersionStage"] == "AWSPREVIOUS":
+			_, _ = w.Write([]byte(`{"SecretString": "previous secret"}`))
+		case input["VersionId"] == "" && input["VersionStage"] == "":
+			_, _ = w.Write([]byte(`{"SecretString": "current secret"}`))
+		default:
+			notFound()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// validSignature reports whether the request, r, was signed with testCreds.
+func validSignature(r *http.Request, body []byte) bool {
+	date, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	auth := r.Header.Get("Authorization")
+
+	i := strings.Index(auth, "SignedHeaders=")
+	if i < 0 {
+		return false
+	}
+
+	signedHeaders := strings.Split(strings.SplitN(auth[i+len("SignedHeaders="):], ",", 2)[0], ";")
+
+	req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		return false
+	}
+
+	for _, name := range signedHeaders {
+		if name != "host" && name != "x-amz-date" {
+			req.Header.Set(name, r.Header.Get(name))
+		}
+	}
+
+	sign(req, body, testCreds, testRegion, service, date)
+
+	return req.Header.Get("Authorization") == auth
+}
+
+func TestProvider(t *testing.T) {
+	server := newTestServer(t)
+
+	getSecret, err := Provider(Config{
+		Region:      testRegion,
+		Endpoint:    server.URL,
+		Credentials: testCreds,
+		now:         func() time.Time { return testNow },
+	})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Default Version",
+			secretName: "db-credentials",
+			version:    secretly.DefaultVersion,
+			want:       "current secret",
+		},
+		{
+			name:       "Version ID",
+			secretName: "db-credentials",
+			version:    testVersionID,
+			want:       "version id secret",
+		},
+		{
+			name:       "Version Stage",
+			secretName: "db-credentials",
+			version:    "AWSPREVIOUS",
+			want:       "previous secret",
+		},
+		{
+			name:       "Binary",
+			secretName: "binary",
+			version:    secretly.DefaultVersion,
+			want:       "\x00\xff,",
+		},
+		{
+			name:       "Missing Version",
+			secretName: "db-credentials",
+			version:    "AWSPENDING",
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Missing Secret",
+			secretName: "api-key",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Throttled",
+			secretName: "throttled",
+			version:    secretly.DefaultVersion,
+			wantErr: secretly.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "ThrottlingException: rate exceeded",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "Missing Region",
+			cfg:     Config{Credentials: testCreds},
+			wantErr: ErrMissingRegion,
+		},
+		{
+			name:    "Missing Credentials",
+			cfg:     Config{Region: testRegion},
+			wantErr: ErrMissingCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Provider(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
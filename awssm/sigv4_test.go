@@ -0,0 +1,30 @@
+package awssm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSign signs the "get-vanilla" request from the AWS Signature Version 4 test suite.
+func TestSign(t *testing.T) {
+	const want = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	creds := Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	sign(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Incorrect Authorization header.\nWant %v\nGot  %v", want, got)
+	}
+}
@@ -0,0 +1,216 @@
+// Package azurekv provides a [secretly.GetSecretFunc] for Azure Key Vault secrets,
+// using its REST API.
+package azurekv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	DefaultAPIVersion = "7.4"
+
+	// maxNameLength is the maximum length of a Key Vault secret name.
+	maxNameLength = 127
+)
+
+var (
+	ErrMissingVaultURL    = errors.New("azurekv: missing vault url")
+	ErrMissingTokenSource = errors.New("azurekv: missing token source")
+)
+
+type (
+	// TokenSource returns an OAuth 2.0 access token, for the Key Vault resource,
+	// used to authorize requests.
+	TokenSource func(ctx context.Context) (string, error)
+
+	// Config configures the Azure Key Vault provider.
+	Config struct {
+		// VaultURL is the Key Vault's URL, e.g. "https://my-vault.vault.azure.net".
+		VaultURL string
+
+		// TokenSource provides the access tokens used to authorize requests.
+		TokenSource TokenSource
+
+		// APIVersion is the version of the Key Vault REST API.
+		// Defaults to [DefaultAPIVersion].
+		APIVersion string
+
+		// Client is the HTTP client used to make requests.
+		// Defaults to [http.DefaultClient].
+		Client *http.Client
+	}
+
+	// provider gets secrets from Key Vault.
+	provider struct {
+		cfg Config
+	}
+)
+
+// StaticToken returns a [TokenSource] which always returns token.
+func StaticToken(token string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets from Azure Key Vault.
+// Key Vault secret names may only contain alphanumeric characters and dashes,
+// so any other characters, like the underscores added by the "split_words" tag,
+// are translated to dashes, see [SecretName].
+//
+// Versions are the secret's version IDs,
+// with [secretly.DefaultVersion] translating to the current version.
+//
+// Missing secrets and versions are reported with errors wrapping
+// [secretly.ErrSecretNotFound]. Other unexpected responses
+// are reported with a [secretly.HTTPError].
+func Provider(cfg Config) (secretly.GetSecretFunc, error) {
+	if cfg.VaultURL == "" {
+		return nil, ErrMissingVaultURL
+	}
+
+	cfg.VaultURL = strings.TrimRight(cfg.VaultURL, "/")
+
+	if cfg.TokenSource == nil {
+		return nil, ErrMissingTokenSource
+	}
+
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = DefaultAPIVersion
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	p := &provider{cfg: cfg}
+
+	return p.getSecret, nil
+}
+
+// SecretName translates name to Key Vault's allowed character set,
+// replacing any characters other than alphanumerics and dashes with dashes,
+// e.g. "My_DB_Credentials" translates to "My-DB-Credentials".
+func SecretName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if isAlphanumeric(r) || r == '-' {
+			return r
+		}
+
+		return '-'
+	}, name)
+}
+
+// getSecret gets the secret's value, implementing [secretly.GetSecretFunc].
+func (p *provider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	translated := SecretName(name)
+	if translated == "" || len(translated) > maxNameLength {
+		return nil, fmt.Errorf("%w: %q", secretly.ErrInvalidSecretName, name)
+	}
+
+	path := "/secrets/" + translated
+
+	if version != secretly.DefaultVersion {
+		if version == "" || strings.IndexFunc(version, func(r rune) bool { return !isAlphanumeric(r) }) >= 0 {
+			return nil, fmt.Errorf("%w: %q", secretly.ErrInvalidSecretVersion, version)
+		}
+
+		path += "/" + version
+	}
+
+	b, err := p.do(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %q version %q: %w", translated, version, err)
+	}
+
+	var resp struct {
+		Value *string `json:"value"`
+	}
+
+	err = json.Unmarshal(b, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret %q version %q: %w", translated, version, err)
+	}
+
+	if resp.Value == nil {
+		return nil, fmt.Errorf("%w: secret %q version %q has no value", secretly.ErrSecretNotFound, translated, version)
+	}
+
+	return []byte(*resp.Value), nil
+}
+
+// do makes an authorized GET request for the path, returning the response body.
+// 404 responses are reported with an error wrapping [secretly.ErrSecretNotFound],
+// and other non-2xx responses with a [secretly.HTTPError].
+func (p *provider) do(ctx context.Context, path string) ([]byte, error) {
+	token, err := p.cfg.TokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting access token: %w", err)
+	}
+
+	u := p.cfg.VaultURL + path + "?" + url.Values{"api-version": {p.cfg.APIVersion}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return b, nil
+	}
+
+	httpErr := secretly.HTTPError{StatusCode: resp.StatusCode, Message: errorMessage(b)}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", secretly.ErrSecretNotFound, httpErr)
+	}
+
+	return nil, httpErr
+}
+
+// errorMessage returns the message from Key Vault's error response body, b.
+func errorMessage(b []byte) string {
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return ""
+	}
+
+	if resp.Error.Code == "" {
+		return resp.Error.Message
+	}
+
+	return resp.Error.Code + ": " + resp.Error.Message
+}
+
+// isAlphanumeric reports whether r is an ASCII letter or digit.
+func isAlphanumeric(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
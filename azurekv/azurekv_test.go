@@ -0,0 +1,170 @@
+package azurekv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	testToken   = "test-token"
+	testVersion = "0123456789abcdef0123456789abcdef"
+)
+
+// newTestServer returns a stand-in for Key Vault's get secret endpoint.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]string{
+		"/secrets/My-DB-Credentials":                `{"value": "current secret", "id": "current"}`,
+		"/secrets/My-DB-Credentials/" + testVersion: `{"value": "versioned secret", "id": "versioned"}`,
+		"/secrets/throttled":                        "",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken || r.URL.Query().Get("api-version") != DefaultAPIVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": {"code": "Unauthorized", "message": "invalid token"}}`))
+			return
+		}
+
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": "SecretNotFound", "message": "not found"}}`))
+			return
+		}
+
+		if resp == "" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"code": "Throttled", "message": "too many requests"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProvider(t *testing.T) {
+	server := newTestServer(t)
+
+	getSecret, err := Provider(Config{VaultURL: server.URL, TokenSource: StaticToken(testToken)})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Default Version",
+			secretName: "My-DB-Credentials",
+			version:    secretly.DefaultVersion,
+			want:       "current secret",
+		},
+		{
+			name:       "Version",
+			secretName: "My-DB-Credentials",
+			version:    testVersion,
+			want:       "versioned secret",
+		},
+		{
+			name:       "Translated Name",
+			secretName: "My_DB_Credentials",
+			version:    secretly.DefaultVersion,
+			want:       "current secret",
+		},
+		{
+			name:       "Missing Secret",
+			secretName: "api-key",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Invalid Version",
+			secretName: "My-DB-Credentials",
+			version:    "../1",
+			wantErr:    secretly.ErrInvalidSecretVersion,
+		},
+		{
+			name:       "Throttled",
+			secretName: "throttled",
+			version:    secretly.DefaultVersion,
+			wantErr: secretly.HTTPError{
+				StatusCode: http.StatusTooManyRequests,
+				Message:    "Throttled: too many requests",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSecretName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "My-DB-Credentials", want: "My-DB-Credentials"},
+		{name: "My_DB_Credentials", want: "My-DB-Credentials"},
+		{name: "app/db.password", want: "app-db-password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SecretName(tt.name); got != tt.want {
+				t.Errorf("Incorrect secret name. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "Missing Vault URL",
+			cfg:     Config{TokenSource: StaticToken(testToken)},
+			wantErr: ErrMissingVaultURL,
+		},
+		{
+			name:    "Missing Token Source",
+			cfg:     Config{VaultURL: "https://my-vault.vault.azure.net"},
+			wantErr: ErrMissingTokenSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Provider(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
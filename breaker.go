@@ -0,0 +1,183 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	// BreakerClosed allows calls, counting consecutive failures.
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen fails calls fast, until the cool-down has elapsed.
+	BreakerOpen
+
+	// BreakerHalfOpen allows a single probe call,
+	// closing the breaker if it succeeds, or reopening it if it fails.
+	BreakerHalfOpen
+)
+
+var (
+	ErrCircuitOpen           = errors.New("circuit breaker open")
+	ErrInvalidCircuitBreaker = errors.New("invalid circuit breaker")
+)
+
+// BreakerState is the state of a [CircuitBreaker].
+type BreakerState int
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker fails calls fast once a number of consecutive calls have failed,
+// protecting a struggling secret manager. After a cool-down the breaker half-opens,
+// allowing a single probe call through to decide whether to close again.
+// A CircuitBreaker is safe for concurrent use,
+// so create one and share it across [Process] calls with [CircuitBreak].
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	coolDown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+
+	// now returns the current time, overridden in tests.
+	now func() time.Time
+}
+
+// NewCircuitBreaker returns a [CircuitBreaker] which opens after threshold
+// consecutive failures, and half-opens once coolDown has elapsed.
+// If threshold is less than 1 or coolDown is negative,
+// [ErrInvalidCircuitBreaker] is returned.
+func NewCircuitBreaker(threshold int, coolDown time.Duration) (*CircuitBreaker, error) {
+	if threshold < 1 || coolDown < 0 {
+		return nil, fmt.Errorf("%w: threshold %d, cool-down %v", ErrInvalidCircuitBreaker, threshold, coolDown)
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		coolDown:  coolDown,
+		now:       time.Now,
+	}, nil
+}
+
+// CircuitBreak returns a [GetSecretFunc] which calls getSecret through the breaker.
+// While the breaker is open, an error wrapping [ErrCircuitOpen] is returned
+// without calling getSecret.
+//
+// Errors wrapping [ErrSecretNotFound], and errors from the caller's context
+// being canceled, do not count as failures.
+func CircuitBreak(getSecret GetSecretFunc, breaker *CircuitBreaker) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		probe, err := breaker.allow()
+		if err != nil {
+			return nil, fmt.Errorf("secret %q version %q: %w", name, version, err)
+		}
+
+		b, err := getSecret(ctx, name, version)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the secret manager's health
+			breaker.release(probe)
+			return nil, err
+		}
+
+		breaker.record(probe, err != nil && !errors.Is(err, ErrSecretNotFound))
+
+		return b, err
+	}
+}
+
+// State returns the breaker's current state, e.g. for health checks.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfCooled()
+
+	return cb.state
+}
+
+// allow reports whether a call is allowed, returning [ErrCircuitOpen] if not,
+// and whether the call is the half-open breaker's probe.
+func (cb *CircuitBreaker) allow() (bool, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfCooled()
+
+	switch cb.state {
+	case BreakerOpen:
+		return false, ErrCircuitOpen
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false, ErrCircuitOpen
+		}
+
+		cb.probing = true
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record records the outcome of an allowed call.
+func (cb *CircuitBreaker) record(probe, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probing = false
+	}
+
+	if !failed {
+		// Calls allowed before the breaker opened
+		// do not close it, only the probe does.
+		if cb.state == BreakerClosed || probe {
+			cb.state = BreakerClosed
+			cb.failures = 0
+		}
+
+		return
+	}
+
+	cb.failures++
+
+	if probe || (cb.state == BreakerClosed && cb.failures >= cb.threshold) {
+		cb.state = BreakerOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// release releases the half-open breaker's probe,
+// without recording an outcome.
+func (cb *CircuitBreaker) release(probe bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probing = false
+	}
+}
+
+// halfOpenIfCooled half-opens the open breaker once the cool-down has elapsed.
+func (cb *CircuitBreaker) halfOpenIfCooled() {
+	if cb.state == BreakerOpen && cb.now().Sub(cb.openedAt) >= cb.coolDown {
+		cb.state = BreakerHalfOpen
+	}
+}
@@ -0,0 +1,150 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreak(t *testing.T) {
+	breaker, err := NewCircuitBreaker(2, time.Minute)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	now := time.Unix(0, 0)
+	breaker.now = func() time.Time { return now }
+
+	var (
+		calls   int
+		failing = true
+	)
+
+	getSecret := CircuitBreak(func(ctx context.Context, name, version string) ([]byte, error) {
+		calls++
+
+		switch {
+		case name == "missing":
+			return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		case failing:
+			return nil, errGetSecret
+		default:
+			return []byte("secret"), nil
+		}
+	}, breaker)
+
+	// Each step is run in order against the same breaker
+	tests := []struct {
+		name       string
+		secretName string
+		elapsed    time.Duration
+		failing    bool
+		wantErr    error
+		wantCalled bool
+		wantState  BreakerState
+	}{
+		{
+			name:       "Not Found Is Not A Failure",
+			secretName: "missing",
+			failing:    true,
+			wantErr:    ErrSecretNotFound,
+			wantCalled: true,
+			wantState:  BreakerClosed,
+		},
+		{
+			name:       "First Failure",
+			secretName: "api-key",
+			failing:    true,
+			wantErr:    errGetSecret,
+			wantCalled: true,
+			wantState:  BreakerClosed,
+		},
+		{
+			name:       "Opens At Threshold",
+			secretName: "api-key",
+			failing:    true,
+			wantErr:    errGetSecret,
+			wantCalled: true,
+			wantState:  BreakerOpen,
+		},
+		{
+			name:       "Fails Fast While Open",
+			secretName: "api-key",
+			failing:    false,
+			wantErr:    ErrCircuitOpen,
+			wantCalled: false,
+			wantState:  BreakerOpen,
+		},
+		{
+			name:       "Failed Probe Reopens",
+			secretName: "api-key",
+			elapsed:    time.Minute,
+			failing:    true,
+			wantErr:    errGetSecret,
+			wantCalled: true,
+			wantState:  BreakerOpen,
+		},
+		{
+			name:       "Successful Probe Closes",
+			secretName: "api-key",
+			elapsed:    time.Minute,
+			failing:    false,
+			wantCalled: true,
+			wantState:  BreakerClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.elapsed)
+			failing = tt.failing
+			before := calls
+
+			_, err := getSecret(context.Background(), tt.secretName, DefaultVersion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if called := calls > before; called != tt.wantCalled {
+				t.Errorf("Incorrect call. Want called %t, got %t", tt.wantCalled, called)
+			}
+
+			if got := breaker.State(); got != tt.wantState {
+				t.Errorf("Incorrect state. Want %v, got %v", tt.wantState, got)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerState(t *testing.T) {
+	breaker, err := NewCircuitBreaker(1, time.Minute)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	now := time.Unix(0, 0)
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(false, true)
+
+	if got := breaker.State(); got != BreakerOpen {
+		t.Fatalf("Incorrect state. Want %v, got %v", BreakerOpen, got)
+	}
+
+	now = now.Add(time.Minute)
+
+	if got := breaker.State(); got != BreakerHalfOpen {
+		t.Fatalf("Incorrect state. Want %v, got %v", BreakerHalfOpen, got)
+	}
+
+	// Only a single probe is allowed while half-open
+	if probe, err := breaker.allow(); !probe || err != nil {
+		t.Fatalf("Incorrect probe. Want %t, %v, got %t, %v", true, nil, probe, err)
+	}
+
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrCircuitOpen, err)
+	}
+}
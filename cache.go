@@ -1,31 +1,182 @@
 package secretly
 
-// secretCacheEntry is a map of versions to the secret content.
-type secretCacheEntry map[string][]byte
+import (
+	"container/list"
+	"sync"
+	"time"
+)
 
-// cache contains the cache, mapping secrets to a [secretCacheEntry].
-type cache struct {
-	cache map[string]secretCacheEntry
+type (
+	// CacheOptions are optional modifiers for [NewCache].
+	CacheOption func(*Cache)
+
+	// Cache caches secret content, keyed by the secret's name and version.
+	// A Cache is safe for concurrent use, so create one and share it
+	// across [Process] calls with [WithSharedCache].
+	//
+	// By default entries never expire and the cache is unbounded.
+	// Bounded caches evict the least recently used entries first.
+	Cache struct {
+		mu         sync.Mutex
+		ttl        time.Duration
+		maxEntries int
+		maxBytes   int
+
+		entries map[secretID]*list.Element
+		lru     *list.List // of *cacheEntry, most recently used first
+		bytes   int
+		stats   CacheStats
+
+		// now returns the current time, overridden in tests.
+		now func() time.Time
+	}
+
+	// CacheStats are a [Cache]'s counters and current size.
+	CacheStats struct {
+		Hits      uint64
+		Misses    uint64
+		Evictions uint64 // entries removed for expiring, or to bound the cache's size
+		Entries   int
+		Bytes     int
+	}
+
+	// cacheEntry is a cached secret's content.
+	cacheEntry struct {
+		id      secretID
+		content []byte
+		expires time.Time
+	}
+)
+
+// WithCacheTTL expires each entry ttl after it is added.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheMaxEntries bounds the number of entries in the cache.
+func WithCacheMaxEntries(n int) CacheOption {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
 }
 
-// newCache constructs a secretCache.
-func newCache() *cache {
-	return &cache{cache: make(map[string]secretCacheEntry)}
+// WithCacheMaxBytes bounds the total size of the secret content in the cache.
+// Secrets larger than n bytes are not cached.
+func WithCacheMaxBytes(n int) CacheOption {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
 }
 
-func (sc cache) Add(name, version string, content []byte) {
-	if sc.cache[name] == nil {
-		sc.cache[name] = make(secretCacheEntry)
+// NewCache constructs a [Cache].
+func NewCache(opts ...CacheOption) *Cache {
+	c := &Cache{
+		entries: make(map[secretID]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
-	sc.cache[name][version] = content
+
+	return c
 }
 
-func (sc cache) Get(name, version string) ([]byte, bool) {
-	if _, ok := sc.cache[name]; !ok {
+// Add caches the content of the secret's version,
+// evicting the least recently used entries if the cache is full.
+func (c *Cache) Add(name, version string, content []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := secretID{name: name, version: version}
+
+	if e, ok := c.entries[id]; ok {
+		c.remove(e)
+	}
+
+	if c.maxBytes > 0 && len(content) > c.maxBytes {
+		return
+	}
+
+	entry := &cacheEntry{id: id, content: content}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+
+	c.entries[id] = c.lru.PushFront(entry)
+	c.bytes += len(content)
+
+	for c.lru.Len() > 0 && (c.maxEntries > 0 && c.lru.Len() > c.maxEntries || c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Get returns the cached content of the secret's version,
+// and whether it was found.
+func (c *Cache) Get(name, version string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[secretID{name: name, version: version}]
+	if !ok {
+		c.stats.Misses++
 		return nil, false
 	}
-	if b, ok := sc.cache[name][version]; ok {
-		return b, true
+
+	entry := e.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(e)
+		c.stats.Evictions++
+		c.stats.Misses++
+		return nil, false
 	}
-	return nil, false
+
+	c.lru.MoveToFront(e)
+	c.stats.Hits++
+
+	return entry.content, true
+}
+
+// Invalidate removes the secret's version from the cache,
+// or every version of the secret if version is empty.
+func (c *Cache) Invalidate(name, version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != "" {
+		if e, ok := c.entries[secretID{name: name, version: version}]; ok {
+			c.remove(e)
+		}
+
+		return
+	}
+
+	for id, e := range c.entries {
+		if id.name == name {
+			c.remove(e)
+		}
+	}
+}
+
+// Stats returns the cache's counters and current size.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.bytes
+
+	return stats
+}
+
+// remove removes the entry's element from the cache.
+func (c *Cache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, entry.id)
+	c.bytes -= len(entry.content)
 }
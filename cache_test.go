@@ -3,6 +3,7 @@ package secretly
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 type secretInfo struct {
@@ -11,13 +12,11 @@ type secretInfo struct {
 	content []byte
 }
 
-func newSecretCacheWithEntries() *cache {
-	sc := newCache()
+func newSecretCacheWithEntries(opts ...CacheOption) *Cache {
+	sc := NewCache(opts...)
 
-	sc.cache["key1"] = secretCacheEntry{
-		"1":      []byte("key1: 1: secret content"),
-		"latest": []byte("key1: latest: secret content"),
-	}
+	sc.Add("key1", "1", []byte("key1: 1: secret content"))
+	sc.Add("key1", "latest", []byte("key1: latest: secret content"))
 
 	return sc
 }
@@ -77,12 +76,7 @@ func TestSecretCacheAdd(t *testing.T) {
 
 			sc.Add(tt.secretInfo.name, tt.secretInfo.version, tt.secretInfo.content)
 
-			entry, ok := sc.cache[tt.secretInfo.name]
-			if !ok {
-				t.Errorf("Missing secret cache entry. Expected an entry for %v", tt.secretInfo)
-			}
-
-			got, ok := entry[tt.secretInfo.version]
+			got, ok := sc.Get(tt.secretInfo.name, tt.secretInfo.version)
 			if !ok {
 				t.Errorf("Missing secret cache entry version. Expected an entry version for %v", tt.want)
 			}
@@ -149,3 +143,130 @@ func TestSecretCacheGet(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretCacheTTL(t *testing.T) {
+	sc := NewCache(WithCacheTTL(time.Minute))
+
+	now := time.Unix(0, 0)
+	sc.now = func() time.Time { return now }
+
+	sc.Add("key1", "1", []byte("key1: 1: secret content"))
+
+	if _, ok := sc.Get("key1", "1"); !ok {
+		t.Fatalf("Incorrect ok. Want %v, got %v", true, ok)
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, ok := sc.Get("key1", "1"); ok {
+		t.Fatalf("Incorrect ok. Want %v, got %v", false, ok)
+	}
+
+	want := CacheStats{Hits: 1, Misses: 1, Evictions: 1}
+	if got := sc.Stats(); got != want {
+		t.Errorf("Incorrect stats. Want %+v, got %+v", want, got)
+	}
+}
+
+func TestSecretCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []CacheOption
+		content   []byte
+		wantKeys  []secretID
+		wantStats CacheStats
+	}{
+		{
+			name:      "Max Entries",
+			opts:      []CacheOption{WithCacheMaxEntries(2)},
+			content:   []byte("key2: 1: secret content"),
+			wantKeys:  []secretID{{"key1", "1"}, {"key2", "1"}},
+			wantStats: CacheStats{Hits: 1, Evictions: 1, Entries: 2, Bytes: 46},
+		},
+		{
+			name:      "Max Bytes",
+			opts:      []CacheOption{WithCacheMaxBytes(60)},
+			content:   []byte("key2: 1: secret content"),
+			wantKeys:  []secretID{{"key1", "1"}, {"key2", "1"}},
+			wantStats: CacheStats{Hits: 1, Evictions: 1, Entries: 2, Bytes: 46},
+		},
+		{
+			name:      "Larger Than Max Bytes",
+			opts:      []CacheOption{WithCacheMaxBytes(60)},
+			content:   make([]byte, 61),
+			wantKeys:  []secretID{{"key1", "1"}, {"key1", "latest"}},
+			wantStats: CacheStats{Hits: 1, Entries: 2, Bytes: 51},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			sc := newSecretCacheWithEntries(tt.opts...)
+
+			// Use version "1", so version "latest" is the least recently used
+			sc.Get("key1", "1")
+			sc.Add("key2", "1", tt.content)
+
+			var got []secretID
+
+			for _, id := range []secretID{{"key1", "1"}, {"key1", "latest"}, {"key2", "1"}} {
+				if _, ok := sc.entries[id]; ok {
+					got = append(got, id)
+				}
+			}
+
+			if !reflect.DeepEqual(tt.wantKeys, got) {
+				t.Errorf("Incorrect cached secrets. Want %v, got %v", tt.wantKeys, got)
+			}
+
+			if got := sc.Stats(); got != tt.wantStats {
+				t.Errorf("Incorrect stats. Want %+v, got %+v", tt.wantStats, got)
+			}
+		})
+	}
+}
+
+func TestSecretCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		version     string
+		wantEntries int
+	}{
+		{
+			name:        "Version",
+			version:     "1",
+			wantEntries: 1,
+		},
+		{
+			name:        "All Versions",
+			version:     "",
+			wantEntries: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			sc := newSecretCacheWithEntries()
+
+			sc.Invalidate("key1", tt.version)
+
+			if _, ok := sc.Get("key1", "1"); ok {
+				t.Errorf("Incorrect ok. Want %v, got %v", false, ok)
+			}
+
+			if got := sc.Stats().Entries; got != tt.wantEntries {
+				t.Errorf("Incorrect entries. Want %d, got %d", tt.wantEntries, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type (
+	// Provider is a named [GetSecretFunc],
+	// composed with other providers using [ChainProvider].
+	Provider struct {
+		Name      string
+		GetSecret GetSecretFunc
+	}
+
+	// ChainOptions are optional modifiers for [ChainProvider].
+	ChainOption func(*chainProvider)
+
+	// chainProvider tries each of its providers in order.
+	chainProvider struct {
+		providers []Provider
+		servedBy  func(provider, name, version string)
+	}
+)
+
+// WithServedBy calls servedBy with the name of the provider
+// which served each secret resolved by the chain.
+func WithServedBy(servedBy func(provider, name, version string)) ChainOption {
+	return func(cp *chainProvider) {
+		cp.servedBy = servedBy
+	}
+}
+
+// ChainProvider returns a [GetSecretFunc] which tries each of the providers in order,
+// returning the secret from the first provider that has it.
+// Only errors wrapping [ErrSecretNotFound] fall through to the next provider,
+// any other error, like a transport error, is returned immediately.
+// If no provider has the secret, an error wrapping [ErrSecretNotFound]
+// and each provider's error is returned.
+//
+// Use this to layer providers, e.g. environment variable overrides,
+// then local files, then a secret manager.
+func ChainProvider(providers []Provider, opts ...ChainOption) GetSecretFunc {
+	cp := &chainProvider{providers: providers}
+
+	for _, opt := range opts {
+		opt(cp)
+	}
+
+	return cp.getSecret
+}
+
+// getSecret gets the secret from the first provider that has it,
+// implementing [GetSecretFunc].
+func (cp *chainProvider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	errs := make([]error, 0, len(cp.providers))
+
+	for _, p := range cp.providers {
+		b, err := p.GetSecret(ctx, name, version)
+		if err == nil {
+			if cp.servedBy != nil {
+				cp.servedBy(p.Name, name, version)
+			}
+
+			return b, nil
+		}
+
+		if !errors.Is(err, ErrSecretNotFound) {
+			return nil, fmt.Errorf("provider %q: %w", p.Name, err)
+		}
+
+		errs = append(errs, fmt.Errorf("provider %q: %w", p.Name, err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: no providers", ErrSecretNotFound)
+	}
+
+	return nil, errors.Join(errs...)
+}
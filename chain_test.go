@@ -0,0 +1,92 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChainProvider(t *testing.T) {
+	overrides := getSecretFromMapManagerNotFound(map[string]map[string]string{
+		"api-key": {
+			"0": "overridden api key",
+		},
+	})
+
+	secretManager := getSecretFromMapManagerNotFound(map[string]map[string]string{
+		"api-key": {
+			"0": "api key",
+		},
+		"db-password": {
+			"0": "db password",
+		},
+	})
+
+	failing := getSecretFromMapManager(nil, errGetSecret)
+
+	tests := []struct {
+		name         string
+		providers    []Provider
+		secretName   string
+		want         string
+		wantProvider string
+		wantErr      error
+	}{
+		{
+			name:         "First Provider",
+			providers:    []Provider{{"overrides", overrides}, {"secret-manager", secretManager}},
+			secretName:   "api-key",
+			want:         "overridden api key",
+			wantProvider: "overrides",
+		},
+		{
+			name:         "Falls Through Not Found",
+			providers:    []Provider{{"overrides", overrides}, {"secret-manager", secretManager}},
+			secretName:   "db-password",
+			want:         "db password",
+			wantProvider: "secret-manager",
+		},
+		{
+			name:       "Not Found",
+			providers:  []Provider{{"overrides", overrides}, {"secret-manager", secretManager}},
+			secretName: "token",
+			wantErr:    ErrSecretNotFound,
+		},
+		{
+			name:       "Does Not Fall Through Other Errors",
+			providers:  []Provider{{"failing", failing}, {"secret-manager", secretManager}},
+			secretName: "db-password",
+			wantErr:    errGetSecret,
+		},
+		{
+			name:       "No Providers",
+			providers:  nil,
+			secretName: "db-password",
+			wantErr:    ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotProvider string
+			servedBy := func(provider, name, version string) {
+				gotProvider = provider
+			}
+
+			getSecret := ChainProvider(tt.providers, WithServedBy(servedBy))
+
+			got, err := getSecret(context.Background(), tt.secretName, DefaultVersion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+
+			if gotProvider != tt.wantProvider {
+				t.Errorf("Incorrect provider. Want %q, got %q", tt.wantProvider, gotProvider)
+			}
+		})
+	}
+}
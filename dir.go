@@ -0,0 +1,123 @@
+package secretly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// kubernetesDataDir is the directory Kubernetes secret volumes atomically
+// swap the secret files in, with each secret file symlinked into it.
+const kubernetesDataDir = "..data"
+
+var ErrInvalidSecretName = errors.New("invalid secret name")
+
+type (
+	// FileOptions are optional modifiers for file backed providers,
+	// like [DirProvider].
+	FileOption func(*fileProvider)
+
+	// fileProvider reads secrets from files in a file system.
+	fileProvider struct {
+		fsys        fs.FS
+		trimNewline bool
+	}
+)
+
+// WithTrimNewline trims a single trailing newline ("\n" or "\r\n")
+// from the contents of each secret file.
+func WithTrimNewline() FileOption {
+	return func(fp *fileProvider) {
+		fp.trimNewline = true
+	}
+}
+
+// DirProvider returns a [GetSecretFunc] which reads secrets from files
+// in the root directory, like Kubernetes secret volumes or Docker's /run/secrets.
+// See [FSProvider] for details on how secrets are resolved to files.
+func DirProvider(root string, opts ...FileOption) GetSecretFunc {
+	return FSProvider(os.DirFS(root), opts...)
+}
+
+// FSProvider returns a [GetSecretFunc] which reads secrets from files in fsys.
+// Secrets are resolved to files with the following logic:
+//
+//	if version == DefaultVersion
+//		name
+//	else
+//		name + "/" + version
+//
+// If the file does not exist, the file is looked up
+// in the "..data" directory used by Kubernetes secret volumes.
+// Secret names and versions must be valid paths, as described by [fs.ValidPath],
+// and versions must not contain slashes, otherwise [ErrInvalidSecretName] is returned.
+// If the file does not exist, an error wrapping [ErrSecretNotFound] is returned.
+func FSProvider(fsys fs.FS, opts ...FileOption) GetSecretFunc {
+	fp := &fileProvider{fsys: fsys}
+
+	for _, opt := range opts {
+		opt(fp)
+	}
+
+	return fp.getSecret
+}
+
+// getSecret reads the secret's file, implementing [GetSecretFunc].
+func (fp *fileProvider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	filePath, err := secretPath(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := fs.ReadFile(fp.fsys, filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		b, err = fs.ReadFile(fp.fsys, path.Join(kubernetesDataDir, filePath))
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+		}
+
+		return nil, fmt.Errorf("reading secret file: %w", err)
+	}
+
+	if fp.trimNewline {
+		b = trimNewline(b)
+	}
+
+	return b, nil
+}
+
+// secretPath returns the path to the file for the secret's version,
+// rejecting names and versions that could escape the file system's root.
+func secretPath(name, version string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSecretName, name)
+	}
+
+	if version == DefaultVersion {
+		return name, nil
+	}
+
+	if !fs.ValidPath(version) || version == "." || strings.Contains(version, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSecretVersion, version)
+	}
+
+	return path.Join(name, version), nil
+}
+
+// trimNewline trims a single trailing newline ("\n" or "\r\n") from b.
+func trimNewline(b []byte) []byte {
+	if !bytes.HasSuffix(b, []byte("\n")) {
+		return b
+	}
+
+	b = b[:len(b)-1]
+
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
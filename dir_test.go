@@ -0,0 +1,117 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSProvider(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api-key":                   {Data: []byte("api key\n")},
+		"db-credentials/1":          {Data: []byte("version 1\r\n")},
+		"db-credentials/2":          {Data: []byte("version 2")},
+		"..data/kubernetes-secret":  {Data: []byte("kubernetes secret\n")},
+		"..2023_01_02/other-secret": {Data: []byte("other secret")},
+	}
+
+	tests := []struct {
+		name        string
+		secretName  string
+		version     string
+		trimNewline bool
+		want        string
+		wantErr     error
+	}{
+		{
+			name:       "Default Version",
+			secretName: "api-key",
+			version:    DefaultVersion,
+			want:       "api key\n",
+		},
+		{
+			name:        "Trim Newline",
+			secretName:  "api-key",
+			version:     DefaultVersion,
+			trimNewline: true,
+			want:        "api key",
+		},
+		{
+			name:        "Version",
+			secretName:  "db-credentials",
+			version:     "1",
+			trimNewline: true,
+			want:        "version 1",
+		},
+		{
+			name:        "Version Without Newline",
+			secretName:  "db-credentials",
+			version:     "2",
+			trimNewline: true,
+			want:        "version 2",
+		},
+		{
+			name:       "Kubernetes Data Directory",
+			secretName: "kubernetes-secret",
+			version:    DefaultVersion,
+			want:       "kubernetes secret\n",
+		},
+		{
+			name:       "Not Found",
+			secretName: "other-secret",
+			version:    DefaultVersion,
+			wantErr:    ErrSecretNotFound,
+		},
+		{
+			name:       "Path Traversal Name",
+			secretName: "../api-key",
+			version:    DefaultVersion,
+			wantErr:    ErrInvalidSecretName,
+		},
+		{
+			name:       "Path Traversal Version",
+			secretName: "db-credentials",
+			version:    "../../api-key",
+			wantErr:    ErrInvalidSecretVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []FileOption
+			if tt.trimNewline {
+				opts = append(opts, WithTrimNewline())
+			}
+
+			got, err := FSProvider(fsys, opts...)(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDirProvider(t *testing.T) {
+	root := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(root, "api-key"), []byte("api key\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	got, err := DirProvider(root, WithTrimNewline())(context.Background(), "api-key", DefaultVersion)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if string(got) != "api key" {
+		t.Errorf("Incorrect secret. Want %q, got %q", "api key", got)
+	}
+}
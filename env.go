@@ -0,0 +1,54 @@
+package secretly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvProvider returns a [GetSecretFunc] which reads secrets from environment variables.
+// Useful for local development, or for overriding secrets
+// when combined with other providers.
+// Environment variables are to be named with the following logic:
+//
+//	if version == DefaultVersion
+//		uppercase( prefix + "_" + name )
+//	else
+//		uppercase( prefix + "_" + name + "_" + version )
+//
+// with any dashes in the name and version replaced by underscores,
+// and the prefix omitted if empty.
+// If the environment variable is not set, an error wrapping [ErrSecretNotFound]
+// is returned.
+func EnvProvider(prefix string) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		var key string
+		if version == DefaultVersion {
+			key = envKey(prefix, name)
+		} else {
+			key = envKey(prefix, name, version)
+		}
+
+		v, ok := os.LookupEnv(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: environment variable %q is not set", ErrSecretNotFound, key)
+		}
+
+		return []byte(v), nil
+	}
+}
+
+// envKey joins the prefix and parts with underscores,
+// returning the uppercase environment variable name
+// with any dashes in the parts replaced by underscores.
+// The prefix is used as is, matching [WithVersionsFromEnv].
+func envKey(prefix string, parts ...string) string {
+	key := strings.ReplaceAll(strings.Join(parts, "_"), "-", "_")
+
+	if prefix != "" {
+		key = prefix + "_" + key
+	}
+
+	return strings.ToUpper(key)
+}
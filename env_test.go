@@ -0,0 +1,64 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("TEST_DB_CREDENTIALS", "default version secret")
+	t.Setenv("TEST_DB_CREDENTIALS_2", "version 2 secret")
+	t.Setenv("API_KEY", "unprefixed secret")
+
+	tests := []struct {
+		name       string
+		prefix     string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Default Version",
+			prefix:     "TEST",
+			secretName: "db-credentials",
+			version:    DefaultVersion,
+			want:       "default version secret",
+		},
+		{
+			name:       "Version",
+			prefix:     "TEST",
+			secretName: "db-credentials",
+			version:    "2",
+			want:       "version 2 secret",
+		},
+		{
+			name:       "No Prefix",
+			prefix:     "",
+			secretName: "Api_Key",
+			version:    DefaultVersion,
+			want:       "unprefixed secret",
+		},
+		{
+			name:       "Not Found",
+			prefix:     "TEST",
+			secretName: "db-credentials",
+			version:    "3",
+			wantErr:    ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnvProvider(tt.prefix)(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
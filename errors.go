@@ -5,11 +5,19 @@ import (
 	"fmt"
 )
 
+// ErrSecretNotFound should be wrapped by a [GetSecretFunc]'s error
+// when the requested secret or version does not exist,
+// to distinguish it from other failures, like transport errors.
+var ErrSecretNotFound = errors.New("secret not found")
+
 var (
 	ErrInvalidSpecification        = errors.New("invalid specification")
 	ErrInvalidSecretType           = errors.New("invalid secret type")
 	ErrInvalidSecretVersion        = errors.New("invalid secret version")
 	ErrSecretTypeDoesNotSupportKey = errors.New("secret type does not support \"key\"")
+	ErrInvalidSeparator            = errors.New("invalid separator")
+	ErrInvalidEncoding             = errors.New("invalid encoding")
+	ErrConflictingTags             = errors.New("conflicting tags")
 )
 
 // StructTagError describes an error resulting from an issue with a struct tag.
@@ -24,3 +32,38 @@ func (e StructTagError) Error() string {
 }
 
 func (e StructTagError) Unwrap() error { return e.Err }
+
+// FieldError describes an error resulting from resolving or setting a field.
+type FieldError struct {
+	Path          string // The Go path to the field, e.g. "Config.Database.Password".
+	SecretName    string
+	SecretVersion string
+	SecretKey     string // Only set for the "json" and "yaml" secret types.
+	SecretType    secretType
+	Err           error
+}
+
+func (e FieldError) Error() string {
+	if e.SecretKey != "" {
+		return fmt.Sprintf("field %q: secret %q version %q key %q: %s", e.Path, e.SecretName, e.SecretVersion, e.SecretKey, e.Err)
+	}
+
+	return fmt.Sprintf("field %q: secret %q version %q: %s", e.Path, e.SecretName, e.SecretVersion, e.Err)
+}
+
+func (e FieldError) Unwrap() error { return e.Err }
+
+// HTTPError describes an unexpected response from a secret manager's HTTP API,
+// returned by the HTTP based providers.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Message)
+}
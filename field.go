@@ -1,6 +1,11 @@
 package secretly
 
 import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,16 +28,38 @@ const (
 	YAML secretType = "yaml"
 
 	// Defaults.
-	DefaultType    = Text
-	DefaultVersion = "0"
+	DefaultType        = Text
+	DefaultVersion     = "0"
+	DefaultSeparator   = ","
+	DefaultKVSeparator = ":"
+
+	// wholeSecretKey is the key used to unmarshal
+	// the entire "json" or "yaml" secret into the field.
+	wholeSecretKey = "-"
+
+	// Supported encodings of secret values.
+	encodingBase64 = "base64"
+	encodingHex    = "hex"
 
 	// Supported tags for modifying secretly's behavior.
-	tagIgnored    = "ignored"
-	tagKey        = "key"
-	tagName       = "name"
-	tagSplitWords = "split_words"
-	tagType       = "type"
-	tagVersion    = "version"
+	tagDefault     = "default"
+	tagEncoding    = "encoding"
+	tagIgnored     = "ignored"
+	tagKey         = "key"
+	tagKVSeparator = "kvsep"
+	tagName        = "name"
+	tagOptional    = "optional"
+	tagRequired    = "required"
+	tagSeparator   = "sep"
+	tagSplitWords  = "split_words"
+	tagType        = "type"
+	tagVersion     = "version"
+)
+
+var (
+	decoderType           = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
 )
 
 var (
@@ -41,9 +68,12 @@ var (
 )
 
 var (
-	ErrInvalidJSONSecret = errors.New("secret is not valid json")
-	ErrInvalidYAMLSecret = errors.New("secret is not valid yaml")
-	ErrSecretMissingKey  = errors.New("secret is missing provided key")
+	ErrInvalidJSONSecret  = errors.New("secret is not valid json")
+	ErrInvalidYAMLSecret  = errors.New("secret is not valid yaml")
+	ErrSecretMissingKey   = errors.New("secret is missing provided key")
+	ErrSecretKeyNotScalar = errors.New("secret key does not reference a scalar value")
+	ErrInvalidCollection  = errors.New("secret is not a valid collection")
+	ErrUnsupportedType    = errors.New("unsupported type")
 )
 
 type fields = []field
@@ -52,32 +82,40 @@ type fields = []field
 // exposing its secretly tag values
 // and reference to the underlying value.
 type field struct {
+	path          string // The Go path to the field, e.g. "Config.Database.Password".
 	secretType    secretType
 	secretName    string
 	secretVersion string
 	mapKeyName    string // NOTE: Only used for JSONType and YAMLType secret types.
 	splitWords    bool
+	separator     string // NOTE: Only used for slice, array and map fields.
+	kvSeparator   string // NOTE: Only used for map fields.
+	encoding      string
+	defaultValue  string
+	hasDefault    bool
+	optional      bool
 	value         reflect.Value
-	cache         *cache
+	cache         *Cache
 }
 
 // newField constructs a field referencing the provided reflect.Value with the tags from
-// the reflect.StructField applied
-func newField(fValue reflect.Value, fStructField reflect.StructField) (field, error) {
+// the reflect.StructField applied. path is the Go path to the field.
+func newField(fValue reflect.Value, fStructField reflect.StructField, path string) (field, error) {
 	var (
 		newField field
 		ok       bool
 		err      error
 	)
 
-	// Set the reference to the field's reflection
+	// Set the reference to the field's reflection and its path
 	newField.value = fValue
+	newField.path = path
 
 	// Get the split_words value, setting it to false if not explicitly set
 	newField.splitWords, ok, err = parseOptionalStructTagKey[bool](fStructField, tagSplitWords)
 	if err != nil {
 		return field{}, StructTagError{
-			Name: fStructField.Name,
+			Name: path,
 			Key:  tagSplitWords,
 			Err:  err,
 		}
@@ -92,7 +130,7 @@ func newField(fValue reflect.Value, fStructField reflect.StructField) (field, er
 	newField.secretType, ok, err = parseOptionalStructTagKey[secretType](fStructField, tagType)
 	if err != nil {
 		return field{}, StructTagError{
-			Name: fStructField.Name,
+			Name: path,
 			Key:  tagType,
 			Err:  err,
 		}
@@ -106,7 +144,7 @@ func newField(fValue reflect.Value, fStructField reflect.StructField) (field, er
 	case Text, JSON, YAML:
 	default:
 		return field{}, StructTagError{
-			Name: fStructField.Name,
+			Name: path,
 			Key:  tagType,
 			Err:  fmt.Errorf("%w: %q", ErrInvalidSecretType, newField.secretType),
 		}
@@ -117,7 +155,7 @@ func newField(fValue reflect.Value, fStructField reflect.StructField) (field, er
 	newField.secretName, ok, err = parseOptionalStructTagKey[string](fStructField, tagName)
 	if err != nil {
 		return field{}, StructTagError{
-			Name: fStructField.Name,
+			Name: path,
 			Key:  tagName,
 			Err:  err,
 		}
@@ -135,7 +173,7 @@ func newField(fValue reflect.Value, fStructField reflect.StructField) (field, er
 		newField.mapKeyName, ok, err = parseOptionalStructTagKey[string](fStructField, tagKey)
 		if err != nil {
 			return field{}, StructTagError{
-				Name: fStructField.Name,
+				Name: path,
 				Key:  tagKey,
 				Err:  err,
 			}
@@ -146,19 +184,122 @@ func newField(fValue reflect.Value, fStructField reflect.StructField) (field, er
 	default:
 		if _, ok = fStructField.Tag.Lookup(tagKey); ok {
 			return field{}, StructTagError{
-				Name: fStructField.Name,
+				Name: path,
 				Key:  tagKey,
 				Err:  ErrSecretTypeDoesNotSupportKey,
 			}
 		}
 	}
 
+	// Get the sep and kvsep values, setting them to their defaults, "," and ":",
+	// if not explicitly set
+	newField.separator, ok, err = parseOptionalStructTagKey[string](fStructField, tagSeparator)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagSeparator,
+			Err:  err,
+		}
+	}
+	if !ok {
+		newField.separator = DefaultSeparator
+	}
+	if newField.separator == "" {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagSeparator,
+			Err:  ErrInvalidSeparator,
+		}
+	}
+
+	newField.kvSeparator, ok, err = parseOptionalStructTagKey[string](fStructField, tagKVSeparator)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagKVSeparator,
+			Err:  err,
+		}
+	}
+	if !ok {
+		newField.kvSeparator = DefaultKVSeparator
+	}
+	if newField.kvSeparator == "" {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagKVSeparator,
+			Err:  ErrInvalidSeparator,
+		}
+	}
+
+	// Get the encoding value, leaving it empty if not explicitly set.
+	// Also perform validation to ensure only valid encodings are provided
+	newField.encoding, _, err = parseOptionalStructTagKey[string](fStructField, tagEncoding)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagEncoding,
+			Err:  err,
+		}
+	}
+
+	switch newField.encoding {
+	case "", encodingBase64, encodingHex:
+	default:
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagEncoding,
+			Err:  fmt.Errorf("%w: %q", ErrInvalidEncoding, newField.encoding),
+		}
+	}
+
+	// Get the default value, used if the secret or key is missing
+	newField.defaultValue, newField.hasDefault, err = parseOptionalStructTagKey[string](fStructField, tagDefault)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagDefault,
+			Err:  err,
+		}
+	}
+
+	// Get the optional and required values, setting the field to required if neither
+	// is explicitly set. optional:"true" and required:"false" are equivalent
+	newField.optional, _, err = parseOptionalStructTagKey[bool](fStructField, tagOptional)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagOptional,
+			Err:  err,
+		}
+	}
+
+	required, ok, err := parseOptionalStructTagKey[bool](fStructField, tagRequired)
+	if err != nil {
+		return field{}, StructTagError{
+			Name: path,
+			Key:  tagRequired,
+			Err:  err,
+		}
+	}
+
+	if ok {
+		if required && newField.optional {
+			return field{}, StructTagError{
+				Name: path,
+				Key:  tagRequired,
+				Err:  ErrConflictingTags,
+			}
+		}
+
+		newField.optional = !required
+	}
+
 	// Get the version value, setting it to the default, "default", if not explicitly
 	// set. Split the words if the default value was used and split_words was set to true
 	newField.secretVersion, ok, err = parseOptionalStructTagKey[string](fStructField, tagVersion)
 	if err != nil {
 		return field{}, StructTagError{
-			Name: fStructField.Name,
+			Name: path,
 			Key:  tagVersion,
 			Err:  err,
 		}
@@ -186,12 +327,28 @@ func (f *field) MapKeyName() string {
 	return f.mapKeyName
 }
 
+// IsWholeSecret reports whether the entire "json" or "yaml" secret
+// is unmarshalled into the field, rather than a single key.
+func (f *field) IsWholeSecret() bool {
+	switch f.secretType {
+	case JSON, YAML:
+		return f.mapKeyName == wholeSecretKey
+	}
+
+	return false
+}
+
 // Name returns the resolved name of the field. If the secret type is "json" or "yaml",
-// the secret name and key name are combined. If "split_words" is true, the combination
-// of secret name and key name are transformed into uppercase, snake case.
+// the secret name and key name are combined, unless the whole secret is unmarshalled
+// into the field. If "split_words" is true, the combination of secret name and key name
+// are transformed into uppercase, snake case.
 func (f *field) Name() string {
 	switch f.secretType {
 	case JSON, YAML:
+		if f.IsWholeSecret() {
+			break
+		}
+
 		var delimiter string
 		if f.splitWords {
 			delimiter = "_"
@@ -203,6 +360,87 @@ func (f *field) Name() string {
 	return f.SecretName()
 }
 
+// getSecret gets the field's secret content with getSecret.
+// If the field has a cache, the cache is consulted first
+// and populated with the content on a miss.
+func (f *field) getSecret(ctx context.Context, getSecret GetSecretFunc) ([]byte, error) {
+	name := f.SecretName()
+
+	if f.cache != nil {
+		if b, ok := f.cache.Get(name, f.secretVersion); ok {
+			return b, nil
+		}
+	}
+
+	b, err := getSecret(ctx, name, f.secretVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.cache != nil {
+		f.cache.Add(name, f.secretVersion, b)
+	}
+
+	return b, nil
+}
+
+// newError wraps err in a [FieldError] describing the field.
+func (f *field) newError(err error) error {
+	fieldErr := FieldError{
+		Path:          f.path,
+		SecretName:    f.SecretName(),
+		SecretVersion: f.secretVersion,
+		SecretType:    f.secretType,
+		Err:           err,
+	}
+
+	switch f.secretType {
+	case JSON, YAML:
+		if !f.IsWholeSecret() {
+			fieldErr.SecretKey = f.MapKeyName()
+		}
+	}
+
+	return fieldErr
+}
+
+// resolve sets the field's reflect.Value with the secret content, b,
+// or reports err, the error resolving the secret content, as a [FieldError].
+// If the secret or key is missing and the field has a default,
+// the field is set with the default instead.
+// Otherwise, if the field is optional, it is left as is.
+func (f *field) resolve(b []byte, err error) error {
+	if err == nil {
+		err = f.Set(b)
+	}
+
+	if err != nil && (errors.Is(err, ErrSecretNotFound) || errors.Is(err, ErrSecretMissingKey)) {
+		switch {
+		case f.hasDefault:
+			err = f.setDefault()
+		case f.optional:
+			err = nil
+		}
+	}
+
+	if err != nil {
+		return f.newError(err)
+	}
+
+	return nil
+}
+
+// setDefault sets the field's reflect.Value with its default value.
+// The default value replaces the value of the key for "json" and "yaml" secrets,
+// unless the whole secret is unmarshalled into the field.
+func (f *field) setDefault() error {
+	if f.IsWholeSecret() {
+		return f.Set([]byte(f.defaultValue))
+	}
+
+	return f.setText([]byte(f.defaultValue))
+}
+
 // Set sets the field's reflect.Value with b.
 func (f *field) Set(b []byte) error {
 	switch f.secretType {
@@ -219,16 +457,84 @@ func (f *field) Set(b []byte) error {
 
 // setText sets the field's underlying value,
 // handling the input as a "text" secret.
+// []byte fields are set with the (decoded) secret content as is.
 func (f *field) setText(b []byte) error {
+	decoded, err := f.decode(b)
+	if err != nil {
+		return err
+	}
+
+	if f.encoding == "" {
+		// Copy the content so zeroing the field, or a type decoding itself
+		// keeping and zeroing the content, can't affect any other fields
+		// (or the cache) sharing the secret content.
+		decoded = bytes.Clone(decoded)
+	}
+
+	if ok, err := f.unmarshal(f.value, decoded); ok {
+		return err
+	}
+
+	if isBytes(f.value.Type()) {
+		f.value.SetBytes(decoded)
+
+		return nil
+	}
+
+	return f.setValue(f.value, string(decoded))
+}
+
+// decode decodes b with the field's encoding.
+// If the field has no encoding, b is returned as is.
+func (f *field) decode(b []byte) ([]byte, error) {
+	var (
+		decoded []byte
+		n       int
+		err     error
+	)
+
+	switch f.encoding {
+	case encodingBase64:
+		decoded = make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+		n, err = base64.StdEncoding.Decode(decoded, b)
+	case encodingHex:
+		decoded = make([]byte, hex.DecodedLen(len(b)))
+		n, err = hex.Decode(decoded, b)
+	default:
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret %q as %s: %w", f.Name(), f.encoding, err)
+	}
+
+	return decoded[:n], nil
+}
+
+// setValue sets fValue, converting s to fValue's type.
+// Slices, arrays and maps are populated by splitting s
+// with the field's separators and converting each element.
+func (f *field) setValue(fValue reflect.Value, s string) error {
 	const failedConvertErrFormat = "failed to convert secret %q to %s: %w"
 
-	byteString := string(b)
+	if ok, err := f.unmarshal(fValue, []byte(s)); ok {
+		return err
+	}
 
-	valueType := f.value.Type()
+	valueType := fValue.Type()
+
+	switch fValue.Kind() {
+	case reflect.Pointer:
+		value := reflect.New(valueType.Elem())
+
+		err := f.setValue(value.Elem(), s)
+		if err != nil {
+			return err
+		}
+
+		fValue.Set(value)
 
-	switch f.value.Kind() {
 	case reflect.String:
-		f.value.SetString(byteString)
+		fValue.SetString(s)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		var (
@@ -236,12 +542,12 @@ func (f *field) setText(b []byte) error {
 			err   error
 		)
 
-		if f.value.Kind() == reflect.Int64 && valueType.PkgPath() == "time" && valueType.Name() == "Duration" {
+		if fValue.Kind() == reflect.Int64 && valueType.PkgPath() == "time" && valueType.Name() == "Duration" {
 			var d time.Duration
-			d, err = time.ParseDuration(byteString)
+			d, err = time.ParseDuration(s)
 			value = int64(d)
 		} else {
-			value, err = strconv.ParseInt(byteString, 0, valueType.Bits())
+			value, err = strconv.ParseInt(s, 0, valueType.Bits())
 		}
 		if err != nil {
 			t := fmt.Sprintf("int%d", valueType.Bits())
@@ -249,72 +555,411 @@ func (f *field) setText(b []byte) error {
 			return fmt.Errorf(failedConvertErrFormat, f.Name(), t, err)
 		}
 
-		f.value.SetInt(value)
+		fValue.SetInt(value)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		value, err := strconv.ParseUint(byteString, 0, valueType.Bits())
+		value, err := strconv.ParseUint(s, 0, valueType.Bits())
 		if err != nil {
 			t := fmt.Sprintf("uint%d", valueType.Bits())
 
 			return fmt.Errorf(failedConvertErrFormat, f.Name(), t, err)
 		}
 
-		f.value.SetUint(value)
+		fValue.SetUint(value)
 
 	case reflect.Bool:
-		value, err := strconv.ParseBool(byteString)
+		value, err := strconv.ParseBool(s)
 		if err != nil {
 			return fmt.Errorf(failedConvertErrFormat, f.Name(), "bool", err)
 		}
 
-		f.value.SetBool(value)
+		fValue.SetBool(value)
 
 	case reflect.Float32, reflect.Float64:
-		value, err := strconv.ParseFloat(byteString, valueType.Bits())
+		value, err := strconv.ParseFloat(s, valueType.Bits())
 		if err != nil {
 			t := fmt.Sprintf("float%d", valueType.Bits())
 
 			return fmt.Errorf(failedConvertErrFormat, f.Name(), t, err)
 		}
 
-		f.value.SetFloat(value)
+		fValue.SetFloat(value)
+
+	case reflect.Slice:
+		if isBytes(valueType) {
+			fValue.SetBytes([]byte(s))
+			break
+		}
+
+		elems := splitElems(s, f.separator)
+
+		value := reflect.MakeSlice(valueType, len(elems), len(elems))
+		for i, elem := range elems {
+			err := f.setValue(value.Index(i), elem)
+			if err != nil {
+				return err
+			}
+		}
+
+		fValue.Set(value)
+
+	case reflect.Array:
+		elems := splitElems(s, f.separator)
+		if len(elems) > fValue.Len() {
+			err := fmt.Errorf("%w: %d elements, want at most %d", ErrInvalidCollection, len(elems), fValue.Len())
+
+			return fmt.Errorf(failedConvertErrFormat, f.Name(), valueType, err)
+		}
+
+		value := reflect.New(valueType).Elem()
+		for i, elem := range elems {
+			err := f.setValue(value.Index(i), elem)
+			if err != nil {
+				return err
+			}
+		}
+
+		fValue.Set(value)
+
+	case reflect.Map:
+		elems := splitElems(s, f.separator)
+
+		value := reflect.MakeMapWithSize(valueType, len(elems))
+		for _, elem := range elems {
+			pair := strings.SplitN(elem, f.kvSeparator, 2)
+			if len(pair) != 2 {
+				err := fmt.Errorf("%w: %q is missing separator %q", ErrInvalidCollection, elem, f.kvSeparator)
+
+				return fmt.Errorf(failedConvertErrFormat, f.Name(), valueType, err)
+			}
+
+			k := reflect.New(valueType.Key()).Elem()
+			err := f.setValue(k, pair[0])
+			if err != nil {
+				return err
+			}
+
+			v := reflect.New(valueType.Elem()).Elem()
+			err = f.setValue(v, pair[1])
+			if err != nil {
+				return err
+			}
+
+			value.SetMapIndex(k, v)
+		}
+
+		fValue.Set(value)
+
+	default:
+		return fmt.Errorf(failedConvertErrFormat, f.Name(), valueType, ErrUnsupportedType)
 	}
 
 	return nil
 }
 
+// unmarshal sets fValue with b if fValue's type implements [Decoder],
+// [encoding.TextUnmarshaler] or [encoding.BinaryUnmarshaler],
+// reporting whether it did.
+func (f *field) unmarshal(fValue reflect.Value, b []byte) (bool, error) {
+	if !fValue.CanAddr() {
+		return false, nil
+	}
+
+	var err error
+
+	switch v := fValue.Addr().Interface().(type) {
+	case Decoder:
+		err = v.DecodeSecret(b)
+	case encoding.TextUnmarshaler:
+		err = v.UnmarshalText(b)
+	case encoding.BinaryUnmarshaler:
+		err = v.UnmarshalBinary(b)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("failed to convert secret %q to %s: %w", f.Name(), fValue.Type(), err)
+	}
+
+	return true, nil
+}
+
+// isDecodable reports whether t, or a pointer to t, implements
+// [Decoder], [encoding.TextUnmarshaler] or [encoding.BinaryUnmarshaler].
+func isDecodable(t reflect.Type) bool {
+	for _, i := range []reflect.Type{decoderType, textUnmarshalerType, binaryUnmarshalerType} {
+		if t.Implements(i) || reflect.PointerTo(t).Implements(i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWholeSecret reports whether the struct field's tags describe
+// a "json" or "yaml" secret that is unmarshalled into the field as a whole.
+func isWholeSecret(structField reflect.StructField) bool {
+	switch secretType(structField.Tag.Get(tagType)) {
+	case JSON, YAML:
+		return structField.Tag.Get(tagKey) == wholeSecretKey
+	}
+
+	return false
+}
+
+// isBytes reports whether t is a byte slice.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// splitElems splits s around each instance of sep.
+// Unlike [strings.Split], an empty s results in no elements.
+func splitElems(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, sep)
+}
+
 // setJSON sets the field's underlying value,
 // handling the input as a "json" secret.
 func (f *field) setJSON(b []byte) error {
-	var secretMap map[string]string
+	if f.IsWholeSecret() {
+		err := json.Unmarshal(b, f.value.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSONSecret, err)
+		}
 
-	err := json.Unmarshal(b, &secretMap)
-	if err != nil {
-		return ErrInvalidJSONSecret
+		return nil
 	}
 
-	if value, ok := secretMap[f.MapKeyName()]; ok {
-		return f.setText([]byte(value))
+	doc, err := decodeJSONDoc(b)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("%w: secret \"%s\" missing \"%s\"", ErrSecretMissingKey, f.SecretName(), f.MapKeyName())
+	return f.setKey(doc)
 }
 
 // setYAML sets the field's underlying value,
 // handling the input as a "yaml" secret
 func (f *field) setYAML(b []byte) error {
-	var secretMap map[string]string
+	if f.IsWholeSecret() {
+		err := yaml.Unmarshal(b, f.value.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidYAMLSecret, err)
+		}
 
-	err := yaml.Unmarshal(b, &secretMap)
+		return nil
+	}
+
+	doc, err := decodeYAMLDoc(b)
 	if err != nil {
-		return ErrInvalidYAMLSecret
+		return err
+	}
+
+	return f.setKey(doc)
+}
+
+// setKey sets the field's underlying value with the scalar value
+// referenced by the field's key in the unmarshalled "json" or "yaml" secret, doc.
+func (f *field) setKey(doc any) error {
+	key := f.MapKeyName()
+
+	value, ok := lookupKey(doc, key)
+	if !ok {
+		return fmt.Errorf("%w: secret \"%s\" missing \"%s\"", ErrSecretMissingKey, f.SecretName(), key)
 	}
 
-	if value, ok := secretMap[f.MapKeyName()]; ok {
-		return f.setText([]byte(value))
+	text, ok := scalarText(value)
+	if !ok {
+		return fmt.Errorf("%w: secret \"%s\" key \"%s\"", ErrSecretKeyNotScalar, f.SecretName(), key)
+	}
+
+	return f.setText([]byte(text))
+}
+
+// decodeJSONDoc decodes the "json" secret, b, for looking up keys,
+// keeping numbers as they are written.
+func decodeJSONDoc(b []byte) (any, error) {
+	var doc any
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&doc)
+	if err != nil || decoder.More() {
+		return nil, ErrInvalidJSONSecret
+	}
+
+	return doc, nil
+}
+
+// decodeYAMLDoc decodes the "yaml" secret, b, for looking up keys,
+// keeping scalars as they are written rather than resolving their types,
+// so e.g. "1.10" isn't read as 1.1, and dates aren't read as times.
+func decodeYAMLDoc(b []byte) (any, error) {
+	var node yaml.Node
+
+	err := yaml.Unmarshal(b, &node)
+	if err != nil {
+		return nil, ErrInvalidYAMLSecret
+	}
+
+	return yamlNodeValue(&node), nil
+}
+
+// yamlNodeValue converts the YAML node, n, to maps, slices and the text of its scalars.
+// null scalars are converted to nil.
+func yamlNodeValue(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+
+		return yamlNodeValue(n.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+
+		// Merged mappings are added first, so the mapping's own keys take precedence
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].ShortTag() != "!!merge" {
+				continue
+			}
+
+			for _, merged := range mergedValues(n.Content[i+1]) {
+				for k, v := range merged {
+					if _, ok := m[k]; !ok {
+						m[k] = v
+					}
+				}
+			}
+		}
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].ShortTag() != "!!merge" {
+				m[n.Content[i].Value] = yamlNodeValue(n.Content[i+1])
+			}
+		}
+
+		return m
+	case yaml.SequenceNode:
+		s := make([]any, len(n.Content))
+		for i, c := range n.Content {
+			s[i] = yamlNodeValue(c)
+		}
+
+		return s
+	case yaml.AliasNode:
+		return yamlNodeValue(n.Alias)
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!null" {
+			return nil
+		}
+
+		return n.Value
+	}
+
+	return nil
+}
+
+// mergedValues returns the mappings merged by the YAML merge key's value, n,
+// either a single mapping or a sequence of mappings.
+func mergedValues(n *yaml.Node) []map[string]any {
+	var merged []map[string]any
+
+	if n.Kind == yaml.SequenceNode {
+		for _, c := range n.Content {
+			merged = append(merged, mergedValues(c)...)
+		}
+
+		return merged
+	}
+
+	if m, ok := yamlNodeValue(n).(map[string]any); ok {
+		merged = append(merged, m)
+	}
+
+	return merged
+}
+
+// lookupKey looks up the value referenced by key in the unmarshalled secret, doc.
+// key is first looked up as a top-level key. Otherwise, key is treated as a path:
+// a JSON Pointer (RFC 6901) if it starts with "/", e.g. "/db/primary/password",
+// or else a dot-separated path, e.g. "db.primary.password".
+// Array elements are referenced by their index, e.g. "db.replicas.0.password".
+// null values are treated as missing.
+func lookupKey(doc any, key string) (any, bool) {
+	if m, ok := doc.(map[string]any); ok {
+		if value, ok := m[key]; ok {
+			return value, value != nil
+		}
+	}
+
+	var segments []string
+
+	switch {
+	case strings.HasPrefix(key, "/"):
+		segments = strings.Split(key[1:], "/")
+		for i, segment := range segments {
+			segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		}
+	case strings.Contains(key, "."):
+		segments = strings.Split(key, ".")
+	default:
+		return nil, false
+	}
+
+	value := doc
+
+	for _, segment := range segments {
+		var ok bool
+
+		switch node := value.(type) {
+		case map[string]any:
+			value, ok = node[segment]
+		case map[any]any:
+			value, ok = node[segment]
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+
+			value, ok = node[i], true
+		}
+
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, value != nil
+}
+
+// scalarText returns the text representation of the scalar value, value,
+// and a bool indicating if value was a scalar.
+func scalarText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
 	}
 
-	return fmt.Errorf("%w: secret \"%s\" missing \"%s\"", ErrSecretMissingKey, f.SecretName(), f.MapKeyName())
+	return "", false
 }
 
 // parseOptionalStructTagKey parses the provided key's value from the struct field,
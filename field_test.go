@@ -0,0 +1,589 @@
+package secretly
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFieldSetText(t *testing.T) {
+	type specification struct {
+		Strings   []string
+		Ints      []int          `sep:";"`
+		Array     [2]string      `sep:" "`
+		Map       map[string]int `sep:"," kvsep:"="`
+		Empty     []string
+		TooLong   [1]string
+		BadMap    map[string]string
+		BadSlice  []int
+		Structs   []struct{ Host string }
+		StructMap map[string]struct{ Host string }
+	}
+
+	tests := []struct {
+		name      string
+		fieldName string
+		secret    string
+		want      any
+		wantErr   error
+	}{
+		{
+			name:      "String Slice",
+			fieldName: "Strings",
+			secret:    "a,b,c",
+			want:      []string{"a", "b", "c"},
+		},
+		{
+			name:      "Int Slice With Separator",
+			fieldName: "Ints",
+			secret:    "1;2;3",
+			want:      []int{1, 2, 3},
+		},
+		{
+			name:      "Array",
+			fieldName: "Array",
+			secret:    "a b",
+			want:      [2]string{"a", "b"},
+		},
+		{
+			name:      "Map With Separators",
+			fieldName: "Map",
+			secret:    "a=1,b=2",
+			want:      map[string]int{"a": 1, "b": 2},
+		},
+		{
+			name:      "Empty Slice",
+			fieldName: "Empty",
+			secret:    "",
+			want:      []string{},
+		},
+		{
+			name:      "Array Too Long",
+			fieldName: "TooLong",
+			secret:    "a,b",
+			wantErr:   ErrInvalidCollection,
+		},
+		{
+			name:      "Map Missing Separator",
+			fieldName: "BadMap",
+			secret:    "a:1,b",
+			wantErr:   ErrInvalidCollection,
+		},
+		{
+			name:      "Invalid Slice Element",
+			fieldName: "BadSlice",
+			secret:    "1,b",
+			wantErr:   strconv.ErrSyntax,
+		},
+		{
+			name:      "Unsupported Slice Element",
+			fieldName: "Structs",
+			secret:    "a,b",
+			wantErr:   ErrUnsupportedType,
+		},
+		{
+			name:      "Unsupported Map Element",
+			fieldName: "StructMap",
+			secret:    "a:b",
+			wantErr:   ErrUnsupportedType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec specification
+
+			f := newTestField(t, &spec, tt.fieldName)
+
+			err := f.setText([]byte(tt.secret))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := f.value.Interface()
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Incorrect value. Want %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewFieldInvalidSeparator(t *testing.T) {
+	type specification struct {
+		Field []string `sep:""`
+	}
+
+	var spec specification
+	specValue := reflect.ValueOf(&spec).Elem()
+
+	_, err := newField(specValue.Field(0), specValue.Type().Field(0), "Field")
+	if !errors.Is(err, ErrInvalidSeparator) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrInvalidSeparator, err)
+	}
+}
+
+// newTestField constructs a field referencing the field, name, of the struct pointer, spec.
+func newTestField(t *testing.T, spec any, name string) field {
+	t.Helper()
+
+	specValue := reflect.ValueOf(spec).Elem()
+
+	structField, ok := specValue.Type().FieldByName(name)
+	if !ok {
+		t.Fatalf("Missing field %q", name)
+	}
+
+	f, err := newField(specValue.FieldByIndex(structField.Index), structField, name)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	return f
+}
+
+func TestFieldSetTextBytes(t *testing.T) {
+	type specification struct {
+		Raw     []byte
+		Base64  []byte `encoding:"base64"`
+		Hex     []byte `encoding:"hex"`
+		Decoded string `encoding:"base64"`
+		Invalid []byte `encoding:"hex"`
+	}
+
+	tests := []struct {
+		name      string
+		fieldName string
+		secret    []byte
+		want      any
+		wantErr   error
+	}{
+		{
+			name:      "Raw",
+			fieldName: "Raw",
+			secret:    []byte{0x00, 0xff, ','},
+			want:      []byte{0x00, 0xff, ','},
+		},
+		{
+			name:      "Base64",
+			fieldName: "Base64",
+			secret:    []byte("AP8s"),
+			want:      []byte{0x00, 0xff, ','},
+		},
+		{
+			name:      "Hex",
+			fieldName: "Hex",
+			secret:    []byte("00ff2c"),
+			want:      []byte{0x00, 0xff, ','},
+		},
+		{
+			name:      "Decoded String",
+			fieldName: "Decoded",
+			secret:    []byte("c2VjcmV0"),
+			want:      "secret",
+		},
+		{
+			name:      "Invalid Hex",
+			fieldName: "Invalid",
+			secret:    []byte("not hex"),
+			wantErr:   hex.InvalidByteError('n'),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec specification
+
+			f := newTestField(t, &spec, tt.fieldName)
+
+			err := f.setText(tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := f.value.Interface()
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Incorrect value. Want %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Raw Is Copied", func(t *testing.T) {
+		var spec specification
+
+		f := newTestField(t, &spec, "Raw")
+
+		secret := []byte("secret")
+		if err := f.setText(secret); err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+
+		secret[0] = 'S'
+		if string(spec.Raw) != "secret" {
+			t.Errorf("Incorrect value. Want %q, got %q", "secret", spec.Raw)
+		}
+	})
+}
+
+func TestNewFieldInvalidEncoding(t *testing.T) {
+	type specification struct {
+		Field []byte `encoding:"base32"`
+	}
+
+	var spec specification
+	specValue := reflect.ValueOf(&spec).Elem()
+
+	_, err := newField(specValue.Field(0), specValue.Type().Field(0), "Field")
+	if !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrInvalidEncoding, err)
+	}
+}
+
+// token is a test type implementing Decoder.
+type token struct {
+	value string
+}
+
+func (t *token) DecodeSecret(b []byte) error {
+	if len(b) == 0 {
+		return errEmptyToken
+	}
+
+	t.value = "token:" + string(b)
+
+	return nil
+}
+
+var errEmptyToken = errors.New("empty token")
+
+func TestFieldSetTextUnmarshalers(t *testing.T) {
+	type specification struct {
+		URL        url.URL
+		URLPointer *url.URL
+		IP         net.IP
+		IPs        []net.IP
+		Prefix     netip.Prefix
+		Time       time.Time
+		Token      token
+		Tokens     map[string]token
+	}
+
+	tests := []struct {
+		name      string
+		fieldName string
+		secret    string
+		want      any
+		wantErr   error
+	}{
+		{
+			name:      "Binary Unmarshaler",
+			fieldName: "URL",
+			secret:    "https://example.com/path",
+			want:      url.URL{Scheme: "https", Host: "example.com", Path: "/path"},
+		},
+		{
+			name:      "Binary Unmarshaler Pointer",
+			fieldName: "URLPointer",
+			secret:    "https://example.com",
+			want:      &url.URL{Scheme: "https", Host: "example.com"},
+		},
+		{
+			name:      "Text Unmarshaler Byte Slice",
+			fieldName: "IP",
+			secret:    "10.0.0.1",
+			want:      net.ParseIP("10.0.0.1"),
+		},
+		{
+			name:      "Text Unmarshaler Slice",
+			fieldName: "IPs",
+			secret:    "10.0.0.1,10.0.0.2",
+			want:      []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")},
+		},
+		{
+			name:      "Text Unmarshaler Struct",
+			fieldName: "Prefix",
+			secret:    "10.0.0.0/8",
+			want:      netip.MustParsePrefix("10.0.0.0/8"),
+		},
+		{
+			name:      "Text Unmarshaler Time",
+			fieldName: "Time",
+			secret:    "2023-01-02T03:04:05Z",
+			want:      time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:      "Decoder",
+			fieldName: "Token",
+			secret:    "abc",
+			want:      token{value: "token:abc"},
+		},
+		{
+			name:      "Decoder Map",
+			fieldName: "Tokens",
+			secret:    "a:1,b:2",
+			want:      map[string]token{"a": {value: "token:1"}, "b": {value: "token:2"}},
+		},
+		{
+			name:      "Decoder Error",
+			fieldName: "Token",
+			secret:    "",
+			wantErr:   errEmptyToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec specification
+
+			f := newTestField(t, &spec, tt.fieldName)
+
+			err := f.setText([]byte(tt.secret))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := f.value.Interface()
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Incorrect value. Want %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+// keptToken is a test type implementing Decoder,
+// keeping the content and zeroing it when cleared.
+type keptToken struct {
+	b []byte
+}
+
+func (t *keptToken) DecodeSecret(b []byte) error {
+	t.b = b
+	return nil
+}
+
+func (t *keptToken) clear() {
+	for i := range t.b {
+		t.b[i] = 0
+	}
+}
+
+func TestFieldSetTextDecoderCopy(t *testing.T) {
+	type specification struct {
+		Token keptToken
+	}
+
+	var spec specification
+
+	f := newTestField(t, &spec, "Token")
+
+	secret := []byte("secret")
+	if err := f.setText(secret); err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	spec.Token.clear()
+	if string(secret) != "secret" {
+		t.Errorf("Incorrect secret content. Want %q, got %q", "secret", secret)
+	}
+}
+
+func TestFieldSetYAMLKeepsScalarText(t *testing.T) {
+	const secret = `
+decimal: 1.10
+hex: 0x1F
+exponent: 1e3
+date: 2024-01-01
+pin: 0123
+merged:
+  <<: {inherited: 1.10}
+`
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "Trailing Zero", key: "decimal", want: "1.10"},
+		{name: "Hex", key: "hex", want: "0x1F"},
+		{name: "Exponent", key: "exponent", want: "1e3"},
+		{name: "Date", key: "date", want: "2024-01-01"},
+		{name: "Leading Zero", key: "pin", want: "0123"},
+		{name: "Merge Key", key: "merged.inherited", want: "1.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value string
+
+			f := field{
+				secretType: YAML,
+				secretName: "secret",
+				mapKeyName: tt.key,
+				value:      reflect.ValueOf(&value).Elem(),
+			}
+
+			err := f.Set([]byte(secret))
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			if value != tt.want {
+				t.Errorf("Incorrect value. Want %q, got %q", tt.want, value)
+			}
+		})
+	}
+}
+
+func TestFieldSetKeyPaths(t *testing.T) {
+	const (
+		jsonSecret = `{
+			"db.name": "literal",
+			"db": {
+				"primary": {"password": "primary password", "port": 5432},
+				"replicas": [{"password": "replica password"}],
+				"enabled": true,
+				"ratio": 0.5,
+				"empty": null
+			},
+			"a/b": {"~c": "escaped"}
+		}`
+		yamlSecret = `
+db:
+  primary:
+    password: primary password
+    port: 5432
+  replicas:
+    - password: replica password
+  enabled: true
+  ratio: 0.5
+`
+	)
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Top-Level Key With Dot",
+			key:  "db.name",
+			want: "literal",
+		},
+		{
+			name: "Dotted Path",
+			key:  "db.primary.password",
+			want: "primary password",
+		},
+		{
+			name: "JSON Pointer",
+			key:  "/db/primary/password",
+			want: "primary password",
+		},
+		{
+			name: "Array Index",
+			key:  "db.replicas.0.password",
+			want: "replica password",
+		},
+		{
+			name: "Number",
+			key:  "db.primary.port",
+			want: "5432",
+		},
+		{
+			name: "Boolean",
+			key:  "/db/enabled",
+			want: "true",
+		},
+		{
+			name: "Float",
+			key:  "db.ratio",
+			want: "0.5",
+		},
+		{
+			name:    "Missing",
+			key:     "db.primary.username",
+			wantErr: ErrSecretMissingKey,
+		},
+		{
+			name:    "Index Out Of Range",
+			key:     "db.replicas.1.password",
+			wantErr: ErrSecretMissingKey,
+		},
+		{
+			name:    "Not Scalar",
+			key:     "db.primary",
+			wantErr: ErrSecretKeyNotScalar,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, secret := range []struct {
+			secretType secretType
+			content    string
+		}{
+			{JSON, jsonSecret},
+			{YAML, yamlSecret},
+		} {
+			if tt.key == "db.name" && secret.secretType == YAML {
+				continue
+			}
+
+			t.Run(tt.name+" "+string(secret.secretType), func(t *testing.T) {
+				var value string
+
+				f := field{
+					secretType: secret.secretType,
+					secretName: "secret",
+					mapKeyName: tt.key,
+					value:      reflect.ValueOf(&value).Elem(),
+				}
+
+				err := f.Set([]byte(secret.content))
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+				}
+
+				if value != tt.want {
+					t.Errorf("Incorrect value. Want %q, got %q", tt.want, value)
+				}
+			})
+		}
+	}
+
+	t.Run("Escaped JSON Pointer", func(t *testing.T) {
+		var value string
+
+		f := field{
+			secretType: JSON,
+			mapKeyName: "/a~1b/~0c",
+			value:      reflect.ValueOf(&value).Elem(),
+		}
+
+		err := f.Set([]byte(jsonSecret))
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+
+		if value != "escaped" {
+			t.Errorf("Incorrect value. Want %q, got %q", "escaped", value)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+// Package gcpsm provides a [secretly.GetSecretFunc] for GCP Secret Manager,
+// using its REST API.
+package gcpsm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	DefaultEndpoint = "https://secretmanager.googleapis.com"
+
+	// latestVersion is the alias for the latest version of a secret.
+	latestVersion = "latest"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	ErrMissingProject     = errors.New("gcpsm: missing project")
+	ErrMissingTokenSource = errors.New("gcpsm: missing token source")
+	ErrChecksumMismatch   = errors.New("gcpsm: secret payload checksum mismatch")
+)
+
+type (
+	// TokenSource returns an OAuth 2.0 access token used to authorize requests.
+	TokenSource func(ctx context.Context) (string, error)
+
+	// Config configures the GCP Secret Manager provider.
+	Config struct {
+		// Project is the ID or number of the project containing the secrets.
+		Project string
+
+		// TokenSource provides the access tokens used to authorize requests.
+		TokenSource TokenSource
+
+		// Endpoint is Secret Manager's endpoint.
+		// Defaults to [DefaultEndpoint].
+		Endpoint string
+
+		// Client is the HTTP client used to make requests.
+		// Defaults to [http.DefaultClient].
+		Client *http.Client
+	}
+
+	// provider gets secrets from Secret Manager.
+	provider struct {
+		cfg Config
+	}
+)
+
+// StaticToken returns a [TokenSource] which always returns token.
+func StaticToken(token string) TokenSource {
+	return func(ctx context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets
+// from GCP Secret Manager. Secret names are the secret's ID within the project,
+// or the secret's full resource name, e.g. "projects/my-project/secrets/my-secret".
+//
+// Versions are the secret's version numbers or aliases,
+// with [secretly.DefaultVersion] translating to "latest".
+// The payload's CRC32C checksum is verified, if returned by the API.
+//
+// Missing secrets and versions are reported with errors wrapping
+// [secretly.ErrSecretNotFound]. Other unexpected responses
+// are reported with a [secretly.HTTPError].
+func Provider(cfg Config) (secretly.GetSecretFunc, error) {
+	if cfg.Project == "" {
+		return nil, ErrMissingProject
+	}
+
+	if cfg.TokenSource == nil {
+		return nil, ErrMissingTokenSource
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	p := &provider{cfg: cfg}
+
+	return p.getSecret, nil
+}
+
+// getSecret accesses the secret version's payload, implementing [secretly.GetSecretFunc].
+func (p *provider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	resource, err := p.versionResource(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := p.do(ctx, resource+":access")
+	if err != nil {
+		return nil, fmt.Errorf("accessing secret %q version %q: %w", name, version, err)
+	}
+
+	var resp struct {
+		Payload struct {
+			Data       []byte  `json:"data"`
+			DataCRC32C *string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+
+	err = json.Unmarshal(b, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret %q version %q: %w", name, version, err)
+	}
+
+	if resp.Payload.DataCRC32C != nil {
+		want, err := strconv.ParseUint(*resp.Payload.DataCRC32C, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("decoding secret %q version %q checksum: %w", name, version, err)
+		}
+
+		if got := crc32.Checksum(resp.Payload.Data, crc32cTable); uint64(got) != want {
+			return nil, fmt.Errorf("%w: secret %q version %q", ErrChecksumMismatch, name, version)
+		}
+	}
+
+	return resp.Payload.Data, nil
+}
+
+// versionResource returns the escaped resource name of the secret's version.
+func (p *provider) versionResource(name, version string) (string, error) {
+	if version == secretly.DefaultVersion {
+		version = latestVersion
+	}
+
+	project, secret := p.cfg.Project, name
+
+	if strings.HasPrefix(name, "projects/") {
+		segments := strings.Split(name, "/")
+		if len(segments) != 4 || segments[2] != "secrets" {
+			return "", fmt.Errorf("%w: %q", secretly.ErrInvalidSecretName, name)
+		}
+
+		project, secret = segments[1], segments[3]
+	}
+
+	if !validSegment(project) || !validSegment(secret) {
+		return "", fmt.Errorf("%w: %q", secretly.ErrInvalidSecretName, name)
+	}
+
+	if !validSegment(version) {
+		return "", fmt.Errorf("%w: %q", secretly.ErrInvalidSecretVersion, version)
+	}
+
+	return "projects/" + url.PathEscape(project) +
+		"/secrets/" + url.PathEscape(secret) +
+		"/versions/" + url.PathEscape(version), nil
+}
+
+// do makes an authorized GET request for the resource, returning the response body.
+// 404 responses are reported with an error wrapping [secretly.ErrSecretNotFound],
+// and other non-2xx responses with a [secretly.HTTPError].
+func (p *provider) do(ctx context.Context, resource string) ([]byte, error) {
+	token, err := p.cfg.TokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting access token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Endpoint+"/v1/"+resource, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return b, nil
+	}
+
+	httpErr := secretly.HTTPError{StatusCode: resp.StatusCode, Message: errorMessage(b)}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", secretly.ErrSecretNotFound, httpErr)
+	}
+
+	return nil, httpErr
+}
+
+// errorMessage returns the message from Secret Manager's error response body, b.
+func errorMessage(b []byte) string {
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return ""
+	}
+
+	if resp.Error.Status == "" {
+		return resp.Error.Message
+	}
+
+	return resp.Error.Status + ": " + resp.Error.Message
+}
+
+// validSegment reports whether s can be used as a segment of a resource name.
+func validSegment(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.Contains(s, "/")
+}
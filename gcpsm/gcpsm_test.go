@@ -0,0 +1,201 @@
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	testProject = "my-project"
+	testToken   = "test-token"
+)
+
+// newTestServer returns a stand-in for Secret Manager's access endpoint.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	payload := func(data string, checksum uint32) string {
+		return `{"payload": {"data": "` + base64.StdEncoding.EncodeToString([]byte(data)) +
+			`", "dataCrc32c": "` + strconv.FormatUint(uint64(checksum), 10) + `"}}`
+	}
+
+	checksum := func(data string) uint32 {
+		return crc32.Checksum([]byte(data), crc32.MakeTable(crc32.Castagnoli))
+	}
+
+	responses := map[string]string{
+		"/v1/projects/my-project/secrets/api-key/versions/latest:access":     payload("latest secret", checksum("latest secret")),
+		"/v1/projects/my-project/secrets/api-key/versions/1:access":          payload("version 1 secret", checksum("version 1 secret")),
+		"/v1/projects/other-project/secrets/api-key/versions/latest:access":  payload("other project secret", checksum("other project secret")),
+		"/v1/projects/my-project/secrets/corrupted/versions/latest:access":   payload("corrupted secret", checksum("secret")),
+		"/v1/projects/my-project/secrets/no-checksum/versions/latest:access": `{"payload": {"data": "c2VjcmV0"}}`,
+		"/v1/projects/my-project/secrets/unavailable/versions/latest:access": "",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": {"code": 401, "message": "invalid token", "status": "UNAUTHENTICATED"}}`))
+			return
+		}
+
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "not found", "status": "NOT_FOUND"}}`))
+			return
+		}
+
+		if resp == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"code": 503, "message": "unavailable", "status": "UNAVAILABLE"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProvider(t *testing.T) {
+	server := newTestServer(t)
+
+	getSecret, err := Provider(Config{
+		Project:     testProject,
+		TokenSource: StaticToken(testToken),
+		Endpoint:    server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Default Version",
+			secretName: "api-key",
+			version:    secretly.DefaultVersion,
+			want:       "latest secret",
+		},
+		{
+			name:       "Version",
+			secretName: "api-key",
+			version:    "1",
+			want:       "version 1 secret",
+		},
+		{
+			name:       "Resource Name",
+			secretName: "projects/other-project/secrets/api-key",
+			version:    secretly.DefaultVersion,
+			want:       "other project secret",
+		},
+		{
+			name:       "No Checksum",
+			secretName: "no-checksum",
+			version:    secretly.DefaultVersion,
+			want:       "secret",
+		},
+		{
+			name:       "Checksum Mismatch",
+			secretName: "corrupted",
+			version:    secretly.DefaultVersion,
+			wantErr:    ErrChecksumMismatch,
+		},
+		{
+			name:       "Missing Version",
+			secretName: "api-key",
+			version:    "2",
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Invalid Name",
+			secretName: "../api-key",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrInvalidSecretName,
+		},
+		{
+			name:       "Unavailable",
+			secretName: "unavailable",
+			version:    secretly.DefaultVersion,
+			wantErr: secretly.HTTPError{
+				StatusCode: http.StatusServiceUnavailable,
+				Message:    "UNAVAILABLE: unavailable",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderTokenSourceError(t *testing.T) {
+	errToken := errors.New("token error")
+
+	getSecret, err := Provider(Config{
+		Project: testProject,
+		TokenSource: func(ctx context.Context) (string, error) {
+			return "", errToken
+		},
+	})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	_, err = getSecret(context.Background(), "api-key", secretly.DefaultVersion)
+	if !errors.Is(err, errToken) {
+		t.Fatalf("Incorrect error. Want %v, got %v", errToken, err)
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "Missing Project",
+			cfg:     Config{TokenSource: StaticToken(testToken)},
+			wantErr: ErrMissingProject,
+		},
+		{
+			name:    "Missing Token Source",
+			cfg:     Config{Project: testProject},
+			wantErr: ErrMissingTokenSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Provider(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
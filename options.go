@@ -6,14 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
 type (
 	// ProcessOptions are optional modifiers for secret processing.
-	ProcessOption func(fields) error
+	ProcessOption func(*processor) error
 
 	unmarshalFunc func([]byte, any) error
 
@@ -23,19 +22,24 @@ type (
 		Key        string     `json:"key" yaml:"key"`
 		Version    string     `json:"version" yaml:"version"`
 		SplitWords bool       `json:"split_words" yaml:"split_words"`
+		Optional   bool       `json:"optional" yaml:"optional"`
+		Default    *string    `json:"default" yaml:"default"`
 	}
 )
 
-var ErrInvalidFileType = errors.New("invalid file type")
+var (
+	ErrInvalidFileType    = errors.New("invalid file type")
+	ErrInvalidConcurrency = errors.New("invalid concurrency")
+)
 
 // WithDefaultVersion overwrites the default version, [secretly.DefaultVersion],
 // with the provided version.
 // Use this to set the default version to aliases like "latest" or "AWSCURRENT".
 func WithDefaultVersion(version string) ProcessOption {
-	return func(fields fields) error {
-		for i, f := range fields {
+	return func(p *processor) error {
+		for i, f := range p.fields {
 			if f.secretVersion == DefaultVersion {
-				fields[i].secretVersion = version
+				p.fields[i].secretVersion = version
 			}
 		}
 
@@ -47,26 +51,68 @@ func WithDefaultVersion(version string) ProcessOption {
 // to avoid unnecessary calls to the secret manager.
 // Do not use this option if you want your application
 // to handle secrets changes without restarting.
+//
+// The cache only lives for the [Process] call,
+// use [WithSharedCache] to share a cache across calls.
 func WithCache() ProcessOption {
-	return func(fields fields) error {
-		cache := newCache()
+	return func(p *processor) error {
+		return WithSharedCache(NewCache())(p)
+	}
+}
 
-		for i := range fields {
-			fields[i].cache = cache
+// WithSharedCache caches secrets in the provided cache,
+// which can be shared across [Process] calls,
+// so each secret is only retrieved once while it is cached.
+// Use [NewCache] options to expire entries and bound the cache's size.
+func WithSharedCache(c *Cache) ProcessOption {
+	return func(p *processor) error {
+		for i := range p.fields {
+			p.fields[i].cache = c
 		}
 
 		return nil
 	}
 }
 
+// WithConcurrency resolves up to n distinct secrets concurrently.
+// Fields referencing the same secret name and version
+// share a single call to the secret manager.
+// Fields are only set once every secret has been resolved,
+// and the first error cancels all outstanding calls.
+//
+// n must be at least 1, otherwise [ErrInvalidConcurrency] is returned.
+func WithConcurrency(n int) ProcessOption {
+	return func(p *processor) error {
+		if n < 1 {
+			return fmt.Errorf("%w: %d", ErrInvalidConcurrency, n)
+		}
+
+		p.concurrency = n
+
+		return nil
+	}
+}
+
+// WithCollectErrors attempts to resolve every field,
+// rather than stopping at the first failure.
+// The returned error lists every failing field
+// and supports [errors.Is] and [errors.As] against each cause.
+func WithCollectErrors() ProcessOption {
+	return func(p *processor) error {
+		p.collectErrors = true
+
+		return nil
+	}
+}
+
 // WithPatch returns an ProcessOption which overwrites
 // the specified/default field values with the provided patch.
 // Can be used to overwrite any of the configurable field values.
 //
 // Must be written in either YAML or YAML compatible JSON
 func WithPatch(patch []byte) ProcessOption {
-	return func(fields fields) error {
-		return setFieldsWithPatch(yaml.Unmarshal, patch, fields)
+	return func(p *processor) error {
+		return setFieldsWithPatch(yaml.Unmarshal, patch, p.fields)
 	}
 }
 
@@ -79,7 +125,7 @@ func WithPatch(patch []byte) ProcessOption {
 //  1. JSON (.json)
 //  2. YAML (.yaml,.yml)
 func WithPatchFile(filePath string) ProcessOption {
-	return func(fields fields) error {
+	return func(p *processor) error {
 		b, err := os.ReadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("reading patch file: %w", err)
@@ -87,9 +133,9 @@ func WithPatchFile(filePath string) ProcessOption {
 
 		switch ext := filepath.Ext(filePath); ext {
 		case ".json":
-			err = setFieldsWithPatch(json.Unmarshal, b, fields)
+			err = setFieldsWithPatch(json.Unmarshal, b, p.fields)
 		case ".yaml", ".yml":
-			err = setFieldsWithPatch(yaml.Unmarshal, b, fields)
+			err = setFieldsWithPatch(yaml.Unmarshal, b, p.fields)
 		default:
 			err = fmt.Errorf("%w: %s", ErrInvalidFileType, ext)
 		}
@@ -132,6 +178,13 @@ func setFieldsWithPatch(unmarshal unmarshalFunc, b []byte, fields fields) error
 		if sc.SplitWords {
 			fields[i].splitWords = sc.SplitWords
 		}
+		if sc.Optional {
+			fields[i].optional = sc.Optional
+		}
+		if sc.Default != nil {
+			fields[i].defaultValue = *sc.Default
+			fields[i].hasDefault = true
+		}
 	}
 
 	return nil
@@ -146,17 +199,12 @@ func setFieldsWithPatch(unmarshal unmarshalFunc, b []byte, fields fields) error
 //	else
 //		uppercase( field.FullName() ) + "_VERSION"
 func WithVersionsFromEnv(prefix string) ProcessOption {
-	return func(fields fields) error {
-		if prefix != "" {
-			prefix += "_"
-		}
-
-		for i, field := range fields {
-			name := strings.ReplaceAll(field.Name(), "-", "_")
-			key := strings.ToUpper(prefix + name + "_VERSION")
+	return func(p *processor) error {
+		for i, field := range p.fields {
+			key := envKey(prefix, field.Name(), "VERSION")
 
 			if v, ok := os.LookupEnv(key); ok {
-				fields[i].secretVersion = v // TODO: Support types other than string
+				p.fields[i].secretVersion = v // TODO: Support types other than string
 			}
 		}
 		return nil
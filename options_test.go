@@ -1,6 +1,7 @@
 package secretly
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -12,7 +13,7 @@ func TestWithDefaultVersion(t *testing.T) {
 		{secretVersion: "1"},
 	}
 
-	err := WithDefaultVersion(newDefault)(fs)
+	err := WithDefaultVersion(newDefault)(&processor{fields: fs})
 	if err != nil {
 		t.Fatalf("Incorrect error. Want %v, Got %v", err, nil)
 	}
@@ -29,7 +30,7 @@ func TestWithDefaultVersion(t *testing.T) {
 func TestWithCache(t *testing.T) {
 	fs := fields{{}}
 
-	err := WithCache()(fs)
+	err := WithCache()(&processor{fields: fs})
 	if err != nil {
 		t.Fatalf("Incorrect error. Want %v, Got %v", err, nil)
 	}
@@ -39,6 +40,22 @@ func TestWithCache(t *testing.T) {
 	}
 }
 
+func TestWithSharedCache(t *testing.T) {
+	fs := fields{{}, {}}
+	cache := NewCache()
+
+	err := WithSharedCache(cache)(&processor{fields: fs})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, Got %v", err, nil)
+	}
+
+	for i, f := range fs {
+		if f.cache != cache {
+			t.Fatalf("Incorrect fields[%d].Cache. Want %p, Got %p", i, cache, f.cache)
+		}
+	}
+}
+
 func TestWithPatch(t *testing.T) {
 	fs := fields{
 		field{
@@ -65,7 +82,7 @@ func TestWithPatch(t *testing.T) {
 	}
 	}`)
 
-	err := WithPatch(patch)(fs)
+	err := WithPatch(patch)(&processor{fields: fs})
 	if err != nil {
 		t.Fatalf("Incorrect error. Want %v, Got %v", nil, err)
 	}
@@ -118,7 +135,7 @@ func TestWithPatchFile(t *testing.T) {
 				},
 			}
 
-			err := WithPatchFile(tt.fileName)(fs)
+			err := WithPatchFile(tt.fileName)(&processor{fields: fs})
 			if err != nil {
 				t.Fatalf("Incorrect error. Want %v, Got %v", nil, err)
 			}
@@ -157,7 +174,7 @@ func TestWithVersionsFromEnv(t *testing.T) {
 	t.Setenv("TEST_SECRET_VERSION", "latest")
 	t.Setenv("TEST_SUPERSECRET_VERSION", "1")
 
-	err := WithVersionsFromEnv("TEST")(fs)
+	err := WithVersionsFromEnv("TEST")(&processor{fields: fs})
 	if err != nil {
 		t.Fatalf("Incorrect error. Want %v, Got %v", nil, err)
 	}
@@ -170,3 +187,60 @@ func TestWithVersionsFromEnv(t *testing.T) {
 		t.Errorf("Incorrect fields[1].SecretVersion. Want %v, got %v", "latest", fs[1].secretVersion)
 	}
 }
+
+func TestWithVersionsFromEnvDashedPrefix(t *testing.T) {
+	fs := fields{
+		field{
+			secretType:    DefaultType,
+			secretName:    "db-password",
+			secretVersion: DefaultVersion,
+		},
+	}
+
+	// Dashes are only replaced in the field's name, not the prefix
+	t.Setenv("MY-APP_DB_PASSWORD_VERSION", "2")
+	t.Setenv("MY_APP_DB_PASSWORD_VERSION", "3")
+
+	err := WithVersionsFromEnv("my-app")(&processor{fields: fs})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, Got %v", nil, err)
+	}
+
+	if fs[0].secretVersion != "2" {
+		t.Errorf("Incorrect fields[0].SecretVersion. Want %v, got %v", "2", fs[0].secretVersion)
+	}
+}
+
+func TestWithConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		wantErr error
+	}{
+		{
+			name:    "Valid",
+			n:       4,
+			wantErr: nil,
+		},
+		{
+			name:    "Zero",
+			n:       0,
+			wantErr: ErrInvalidConcurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := processor{}
+
+			err := WithConcurrency(tt.n)(&p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr == nil && p.concurrency != tt.n {
+				t.Errorf("Incorrect concurrency. Want %v, got %v", tt.n, p.concurrency)
+			}
+		})
+	}
+}
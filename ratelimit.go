@@ -0,0 +1,121 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// RateLimiter is a token bucket rate limiter, refilled at a constant rate
+// up to its burst size. A RateLimiter is safe for concurrent use,
+// so create one and share it across [Process] calls,
+// and providers, with [RateLimit].
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// now returns the current time, overridden in tests.
+	now func() time.Time
+}
+
+// NewRateLimiter returns a [RateLimiter] allowing rate requests per second,
+// with bursts of up to burst requests. The bucket starts full.
+// If rate is not positive or burst is less than 1,
+// [ErrInvalidRateLimit] is returned.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if rate <= 0 || burst < 1 {
+		return nil, fmt.Errorf("%w: rate %v, burst %d", ErrInvalidRateLimit, rate, burst)
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}, nil
+}
+
+// RateLimit returns a [GetSecretFunc] which waits for a token from the limiter
+// before each call to getSecret. If the context is done while waiting,
+// the context's error is returned.
+func RateLimit(getSecret GetSecretFunc, limiter *RateLimiter) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		err := limiter.Wait(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for rate limiter: %w", err)
+		}
+
+		return getSecret(ctx, name, version)
+	}
+}
+
+// Wait blocks until a token is available, taking it,
+// or until the context is done, returning the context's error.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	wait := rl.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, returning how long to wait until it is available.
+// The bucket goes into debt while tokens are reserved,
+// so waiters are served in the order they arrive.
+func (rl *RateLimiter) reserve() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token which was not used.
+func (rl *RateLimiter) cancel() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+
+	rl.tokens++
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+}
+
+// refill adds the tokens accrued since the last refill, up to the burst size.
+func (rl *RateLimiter) refill() {
+	now := rl.now()
+
+	if !rl.last.IsZero() {
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+	}
+
+	rl.last = now
+}
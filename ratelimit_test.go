@@ -0,0 +1,91 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	limiter, err := NewRateLimiter(2, 2)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	now := time.Unix(0, 0)
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{name: "Burst", want: 0},
+		{name: "Burst Remaining", want: 0},
+		{name: "Empty", want: 500 * time.Millisecond},
+		{name: "Queued", want: time.Second},
+		{name: "Refilled", elapsed: 2 * time.Second, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.elapsed)
+
+			if got := limiter.reserve(); got != tt.want {
+				t.Errorf("Incorrect wait. Want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter, err := NewRateLimiter(0.001, 1)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	getSecret := RateLimit(getSecretFromMapManager(map[string]map[string]string{
+		"api-key": {"0": "api key"},
+	}, nil), limiter)
+
+	got, err := getSecret(context.Background(), "api-key", DefaultVersion)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if string(got) != "api key" {
+		t.Errorf("Incorrect secret. Want %q, got %q", "api key", got)
+	}
+
+	// The bucket is empty, so the next call waits until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = getSecret(ctx, "api-key", DefaultVersion)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Incorrect error. Want %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		wantErr error
+	}{
+		{name: "Valid", rate: 10, burst: 5},
+		{name: "Zero Rate", rate: 0, burst: 5, wantErr: ErrInvalidRateLimit},
+		{name: "Zero Burst", rate: 10, burst: 0, wantErr: ErrInvalidRateLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRateLimiter(tt.rate, tt.burst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts    = 4
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 5 * time.Second
+)
+
+type (
+	// RetryOptions are optional modifiers for [Retry].
+	RetryOption func(*retryProvider)
+
+	// retryProvider retries its provider's retryable errors with backoff.
+	retryProvider struct {
+		getSecret      GetSecretFunc
+		maxAttempts    int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		timeout        time.Duration
+		isRetryable    func(error) bool
+	}
+)
+
+// WithMaxAttempts sets the maximum number of attempts to get each secret,
+// including the first. Defaults to [DefaultMaxAttempts].
+// Values less than 1 are treated as 1.
+func WithMaxAttempts(n int) RetryOption {
+	return func(rp *retryProvider) {
+		rp.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the backoff before the first retry, initial,
+// which doubles with each retry up to max.
+// Defaults to [DefaultInitialBackoff] and [DefaultMaxBackoff].
+func WithBackoff(initial, max time.Duration) RetryOption {
+	return func(rp *retryProvider) {
+		rp.initialBackoff = initial
+		rp.maxBackoff = max
+	}
+}
+
+// WithRetryTimeout bounds the total time spent getting each secret,
+// including retries. The context's deadline is always respected.
+func WithRetryTimeout(timeout time.Duration) RetryOption {
+	return func(rp *retryProvider) {
+		rp.timeout = timeout
+	}
+}
+
+// WithIsRetryable replaces the classifier deciding which errors are retried,
+// which defaults to [IsRetryable].
+// Errors wrapping [ErrSecretNotFound] are never retried.
+func WithIsRetryable(isRetryable func(error) bool) RetryOption {
+	return func(rp *retryProvider) {
+		rp.isRetryable = isRetryable
+	}
+}
+
+// Retry returns a [GetSecretFunc] which retries getSecret's retryable errors,
+// like transient 5xx and throttling responses, with exponential backoff and jitter.
+// Retrying stops once the maximum attempts are made,
+// or the context is done or would be done before the next attempt,
+// returning the last error.
+//
+// Errors wrapping [ErrSecretNotFound] are never retried.
+func Retry(getSecret GetSecretFunc, opts ...RetryOption) GetSecretFunc {
+	rp := &retryProvider{
+		getSecret:      getSecret,
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		isRetryable:    IsRetryable,
+	}
+
+	for _, opt := range opts {
+		opt(rp)
+	}
+
+	return rp.getSecretWithRetry
+}
+
+// IsRetryable reports whether the error is likely transient:
+// a network error, or an [HTTPError] for a 5xx, 408 (Request Timeout),
+// 429 (Too Many Requests) or AWS throttling response.
+// Context errors and errors wrapping [ErrSecretNotFound] are not retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrSecretNotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode >= 500,
+			httpErr.StatusCode == http.StatusRequestTimeout,
+			httpErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		}
+
+		// AWS reports throttling with a 400 status
+		return strings.HasPrefix(httpErr.Message, "ThrottlingException")
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// getSecretWithRetry gets the secret, retrying retryable errors,
+// implementing [GetSecretFunc].
+func (rp *retryProvider) getSecretWithRetry(ctx context.Context, name, version string) ([]byte, error) {
+	if rp.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rp.timeout)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		b, err := rp.getSecret(ctx, name, version)
+		if err == nil {
+			return b, nil
+		}
+
+		if attempt >= rp.maxAttempts || errors.Is(err, ErrSecretNotFound) || !rp.isRetryable(err) {
+			return nil, err
+		}
+
+		backoff := rp.backoff(attempt)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return nil, fmt.Errorf("retry deadline exceeded after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("retry canceled after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// backoff returns the backoff before the retry following attempt,
+// doubling the initial backoff with each attempt, up to the maximum backoff.
+// Half the backoff is random jitter, spreading out retries from many clients.
+func (rp *retryProvider) backoff(attempt int) time.Duration {
+	backoff := rp.initialBackoff
+	for i := 1; i < attempt && backoff < rp.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > rp.maxBackoff {
+		backoff = rp.maxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
@@ -0,0 +1,137 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// getSecretFailing returns a GetSecretFunc which fails with err
+// for the first failures calls, counting each call in calls.
+func getSecretFailing(failures int, err error, calls *int) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		*calls++
+		if *calls <= failures {
+			return nil, err
+		}
+
+		return []byte("secret"), nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	unavailable := HTTPError{StatusCode: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		opts      []RetryOption
+		want      string
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "Retries Transient Errors",
+			failures:  2,
+			err:       unavailable,
+			want:      "secret",
+			wantCalls: 3,
+		},
+		{
+			name:      "Retries Throttling",
+			failures:  1,
+			err:       fmt.Errorf("getting secret: %w", HTTPError{StatusCode: http.StatusBadRequest, Message: "ThrottlingException: Rate exceeded"}),
+			want:      "secret",
+			wantCalls: 2,
+		},
+		{
+			name:      "Max Attempts",
+			failures:  5,
+			err:       unavailable,
+			opts:      []RetryOption{WithMaxAttempts(2)},
+			wantErr:   unavailable,
+			wantCalls: 2,
+		},
+		{
+			name:      "Does Not Retry Not Found",
+			failures:  1,
+			err:       fmt.Errorf("%w: %w", ErrSecretNotFound, HTTPError{StatusCode: http.StatusNotFound}),
+			opts:      []RetryOption{WithIsRetryable(func(error) bool { return true })},
+			wantErr:   ErrSecretNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:      "Does Not Retry Client Errors",
+			failures:  1,
+			err:       HTTPError{StatusCode: http.StatusForbidden},
+			wantErr:   HTTPError{StatusCode: http.StatusForbidden},
+			wantCalls: 1,
+		},
+		{
+			name:      "Custom Classifier",
+			failures:  1,
+			err:       errGetSecret,
+			opts:      []RetryOption{WithIsRetryable(func(err error) bool { return errors.Is(err, errGetSecret) })},
+			want:      "secret",
+			wantCalls: 2,
+		},
+		{
+			name:      "Retry Timeout",
+			failures:  5,
+			err:       unavailable,
+			opts:      []RetryOption{WithBackoff(time.Hour, time.Hour), WithRetryTimeout(time.Second)},
+			wantErr:   unavailable,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+
+			opts := append([]RetryOption{WithBackoff(time.Millisecond, 2*time.Millisecond)}, tt.opts...)
+			getSecret := Retry(getSecretFailing(tt.failures, tt.err, &calls), opts...)
+
+			got, err := getSecret(context.Background(), "api-key", DefaultVersion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("Incorrect number of calls. Want %d, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	rp := &retryProvider{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 2, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			got := rp.backoff(tt.attempt)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Incorrect backoff. Want between %v and %v, got %v", tt.min, tt.max, got)
+			}
+		})
+	}
+}
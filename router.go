@@ -0,0 +1,113 @@
+package secretly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownScheme = errors.New("unknown secret scheme")
+
+// RouterProvider returns a [GetSecretFunc] which dispatches each secret
+// to the provider registered in routes for the scheme of the secret's name,
+// e.g. "env://API_KEY", "file:///run/secrets/api-key" or "vault://kv/app#password".
+// Secret names without a scheme are dispatched to the provider registered
+// for the empty scheme, "", if any.
+//
+// The provider is passed the remainder of the name, without the scheme,
+// leading slashes or fragment, e.g. "API_KEY", "run/secrets/api-key" and "kv/app".
+// If the name has a fragment, the secret is handled as a "json" or "yaml" secret
+// and the value of the fragment's key is returned, e.g. "password".
+// Fragments support the same nested key paths as the "key" tag.
+//
+// If no provider is registered for the scheme, [ErrUnknownScheme] is returned.
+func RouterProvider(routes map[string]GetSecretFunc) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		scheme, path, fragment := parseSecretURI(name)
+
+		getSecret, ok := routes[scheme]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownScheme, scheme)
+		}
+
+		b, err := getSecret(ctx, path, version)
+		if err != nil {
+			return nil, err
+		}
+
+		if fragment == "" {
+			return b, nil
+		}
+
+		return extractKey(b, fragment)
+	}
+}
+
+// parseSecretURI splits the secret name, name, into its lowercase scheme,
+// its path without leading slashes, and its fragment.
+// If name has no scheme, the scheme is empty and the path is name as is.
+func parseSecretURI(name string) (scheme, path, fragment string) {
+	i := strings.Index(name, "://")
+	if i <= 0 || !isScheme(name[:i]) {
+		return "", name, ""
+	}
+
+	scheme, path = strings.ToLower(name[:i]), strings.TrimLeft(name[i+len("://"):], "/")
+
+	if j := strings.LastIndex(path, "#"); j >= 0 {
+		path, fragment = path[:j], path[j+1:]
+	}
+
+	return scheme, path, fragment
+}
+
+// isScheme reports whether s is a valid URI scheme, as described by RFC 3986.
+func isScheme(s string) bool {
+	for i, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9', c == '+', c == '-', c == '.':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// extractKey returns the scalar value referenced by key
+// in the "json" or "yaml" secret content, b.
+// The content is decoded the same way as "json" and "yaml" fields' secrets,
+// so a fragment and a field's key always agree.
+func extractKey(b []byte, key string) ([]byte, error) {
+	var (
+		doc any
+		err error
+	)
+
+	if json.Valid(b) {
+		doc, err = decodeJSONDoc(b)
+	} else {
+		doc, err = decodeYAMLDoc(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := lookupKey(doc, key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSecretMissingKey, key)
+	}
+
+	text, ok := scalarText(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSecretKeyNotScalar, key)
+	}
+
+	return []byte(text), nil
+}
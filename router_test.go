@@ -0,0 +1,125 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRouterProvider(t *testing.T) {
+	vault := getSecretFromMapManagerNotFound(map[string]map[string]string{
+		"kv/app": {
+			"0": `{"password": "vault password", "db": {"port": 5432}, "ver": 1.10}`,
+		},
+		"kv/yaml": {
+			"0": "ver: 1.10\npin: 0123\ndate: 2024-01-01",
+		},
+	})
+
+	env := getSecretFromMapManagerNotFound(map[string]map[string]string{
+		"API_KEY": {
+			"0": "env api key",
+		},
+	})
+
+	files := getSecretFromMapManagerNotFound(map[string]map[string]string{
+		"run/secrets/api-key": {
+			"1": "file api key",
+		},
+	})
+
+	getSecret := RouterProvider(map[string]GetSecretFunc{
+		"":      env,
+		"env":   env,
+		"file":  files,
+		"vault": vault,
+	})
+
+	tests := []struct {
+		name       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Env",
+			secretName: "env://API_KEY",
+			version:    DefaultVersion,
+			want:       "env api key",
+		},
+		{
+			name:       "File",
+			secretName: "file:///run/secrets/api-key",
+			version:    "1",
+			want:       "file api key",
+		},
+		{
+			name:       "Fragment",
+			secretName: "vault://kv/app#password",
+			version:    DefaultVersion,
+			want:       "vault password",
+		},
+		{
+			name:       "Nested Fragment",
+			secretName: "VAULT://kv/app#db.port",
+			version:    DefaultVersion,
+			want:       "5432",
+		},
+		{
+			name:       "JSON Fragment Keeps Number Text",
+			secretName: "vault://kv/app#ver",
+			version:    DefaultVersion,
+			want:       "1.10",
+		},
+		{
+			name:       "YAML Fragment Keeps Scalar Text",
+			secretName: "vault://kv/yaml#pin",
+			version:    DefaultVersion,
+			want:       "0123",
+		},
+		{
+			name:       "YAML Fragment Date",
+			secretName: "vault://kv/yaml#date",
+			version:    DefaultVersion,
+			want:       "2024-01-01",
+		},
+		{
+			name:       "Missing Fragment Key",
+			secretName: "vault://kv/app#username",
+			version:    DefaultVersion,
+			wantErr:    ErrSecretMissingKey,
+		},
+		{
+			name:       "No Scheme",
+			secretName: "API_KEY",
+			version:    DefaultVersion,
+			want:       "env api key",
+		},
+		{
+			name:       "Unknown Scheme",
+			secretName: "aws://api-key",
+			version:    DefaultVersion,
+			wantErr:    ErrUnknownScheme,
+		},
+		{
+			name:       "Not Found",
+			secretName: "vault://kv/other",
+			version:    DefaultVersion,
+			wantErr:    ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Package secretfile provides a [secretly.GetSecretFunc] for local,
+// encrypted secrets files, which can be checked in
+// for use in development and CI without access to a secret manager.
+//
+// Secrets files are JSON or YAML documents mapping secret names to versions,
+// and versions to values, with each value encrypted with AES-256-GCM:
+//
+//	api-key:
+//	  "0": aesgcm:2b3aKx...
+//	  "5": aesgcm:Vv0bW1...
+//
+// The secret name and version are authenticated with each value,
+// so encrypted values can't be moved between entries.
+// Use [File] to create and edit secrets files.
+package secretfile
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jack-mcveigh/secretly"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KeySize is the size, in bytes, of the AES-256 keys used to encrypt values.
+	KeySize = 32
+
+	// valuePrefix prefixes each encrypted value, identifying its format.
+	valuePrefix = "aesgcm:"
+)
+
+var (
+	ErrInvalidKey   = errors.New("secretfile: invalid key")
+	ErrInvalidValue = errors.New("secretfile: invalid encrypted value")
+	ErrDecrypt      = errors.New("secretfile: decrypting value")
+)
+
+// File is a decoded secrets file,
+// mapping secret names to versions, and versions to encrypted values.
+type File map[string]map[string]string
+
+// GenerateKey returns a new, random key, encoded with base64
+// as expected by [ParseKey].
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("generating key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes the base64 encoded key, s,
+// ignoring surrounding whitespace.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: want %d bytes, got %d", ErrInvalidKey, KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// KeyFromEnv parses the key stored in the environment variable, name.
+func KeyFromEnv(name string) ([]byte, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: environment variable %q not set", ErrInvalidKey, name)
+	}
+
+	return ParseKey(s)
+}
+
+// KeyFromFile parses the key stored in the file at path.
+func KeyFromFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+
+	return ParseKey(string(b))
+}
+
+// ReadFile reads the secrets file at path.
+// The file is decoded as JSON or YAML, based on its extension.
+func ReadFile(path string) (File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets file: %w", err)
+	}
+
+	f := make(File)
+
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		err = json.Unmarshal(b, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &f)
+	default:
+		err = fmt.Errorf("%w: %s", secretly.ErrInvalidFileType, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding secrets file: %w", err)
+	}
+
+	return f, nil
+}
+
+// WriteFile writes the secrets file to path, readable only by its owner.
+// The file is encoded as JSON or YAML, based on its extension.
+func (f File) WriteFile(path string) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		b, err = json.MarshalIndent(f, "", "  ")
+		b = append(b, '\n')
+	case ".yaml", ".yml":
+		b, err = yaml.Marshal(f)
+	default:
+		err = fmt.Errorf("%w: %s", secretly.ErrInvalidFileType, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding secrets file: %w", err)
+	}
+
+	err = os.WriteFile(path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing secrets file: %w", err)
+	}
+
+	return nil
+}
+
+// Set encrypts the value with key,
+// storing it as the specified version of the secret.
+func (f File) Set(key []byte, name, version string, value []byte) error {
+	encrypted, err := Encrypt(key, name, version, value)
+	if err != nil {
+		return err
+	}
+
+	if f[name] == nil {
+		f[name] = make(map[string]string)
+	}
+
+	f[name][version] = encrypted
+
+	return nil
+}
+
+// Get decrypts the specified version of the secret with key.
+// If the secret or version does not exist,
+// an error wrapping [secretly.ErrSecretNotFound] is returned.
+func (f File) Get(key []byte, name, version string) ([]byte, error) {
+	encrypted, ok := f[name][version]
+	if !ok {
+		return nil, fmt.Errorf("%w: secret %q version %q", secretly.ErrSecretNotFound, name, version)
+	}
+
+	return Decrypt(key, name, version, encrypted)
+}
+
+// Delete removes the specified version of the secret,
+// removing the secret entirely once it has no versions left.
+func (f File) Delete(name, version string) {
+	delete(f[name], version)
+
+	if len(f[name]) == 0 {
+		delete(f, name)
+	}
+}
+
+// Encrypt encrypts the value with key for the specified version of the secret,
+// returning it in the format stored in secrets files.
+func Encrypt(key []byte, name, version string, value []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	b := aead.Seal(nonce, nonce, value, additionalData(name, version))
+
+	return valuePrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decrypt decrypts the value, encrypted with [Encrypt],
+// for the specified version of the secret with key.
+func Decrypt(key []byte, name, version, value string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := strings.CutPrefix(value, valuePrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: secret %q version %q: missing %q prefix", ErrInvalidValue, name, version, valuePrefix)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret %q version %q: %v", ErrInvalidValue, name, version, err)
+	}
+
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: secret %q version %q: too short", ErrInvalidValue, name, version)
+	}
+
+	nonce, ciphertext := b[:aead.NonceSize()], b[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(name, version))
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret %q version %q: %v", ErrDecrypt, name, version, err)
+	}
+
+	return plaintext, nil
+}
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets
+// from the secrets file at path, decrypting them with key.
+// The file is read once, when the provider is created.
+//
+// The default version, [secretly.DefaultVersion], is stored as version "0".
+// If the secret or version does not exist,
+// an error wrapping [secretly.ErrSecretNotFound] is returned.
+func Provider(path string, key []byte) (secretly.GetSecretFunc, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: want %d bytes, got %d", ErrInvalidKey, KeySize, len(key))
+	}
+
+	f, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		return f.Get(key, name, version)
+	}, nil
+}
+
+// newAEAD returns the AES-GCM cipher for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: want %d bytes, got %d", ErrInvalidKey, KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData returns the data authenticated with the value
+// of the specified version of the secret,
+// binding the encrypted value to its entry.
+func additionalData(name, version string) []byte {
+	return []byte(name + "\x00" + version)
+}
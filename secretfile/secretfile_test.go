@@ -0,0 +1,214 @@
+package secretfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+// newTestKey returns a new, random key.
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+
+	s, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	key, err := ParseKey(s)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	return key
+}
+
+func TestProvider(t *testing.T) {
+	key := newTestKey(t)
+
+	f := make(File)
+	for _, entry := range []struct{ name, version, value string }{
+		{name: "api-key", version: secretly.DefaultVersion, value: "current key"},
+		{name: "api-key", version: "5", value: "versioned key"},
+	} {
+		err := f.Set(key, entry.name, entry.version, []byte(entry.value))
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "JSON Default Version",
+			file:       "secrets.json",
+			secretName: "api-key",
+			version:    secretly.DefaultVersion,
+			want:       "current key",
+		},
+		{
+			name:       "YAML Version",
+			file:       "secrets.yaml",
+			secretName: "api-key",
+			version:    "5",
+			want:       "versioned key",
+		},
+		{
+			name:       "Missing Secret",
+			file:       "secrets.yaml",
+			secretName: "db-password",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Missing Version",
+			file:       "secrets.json",
+			secretName: "api-key",
+			version:    "6",
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+
+			err := f.WriteFile(path)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			getSecret, err := Provider(path, key)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	key := newTestKey(t)
+
+	encrypted, err := Encrypt(key, "api-key", "5", []byte("secret"))
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		version string
+		value   string
+		want    []byte
+		wantErr error
+	}{
+		{
+			name:    "Valid",
+			key:     key,
+			version: "5",
+			value:   encrypted,
+			want:    []byte("secret"),
+		},
+		{
+			name:    "Wrong Key",
+			key:     newTestKey(t),
+			version: "5",
+			value:   encrypted,
+			wantErr: ErrDecrypt,
+		},
+		{
+			name:    "Moved Entry",
+			key:     key,
+			version: "6",
+			value:   encrypted,
+			wantErr: ErrDecrypt,
+		},
+		{
+			name:    "Plaintext Value",
+			key:     key,
+			version: "5",
+			value:   "secret",
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "Invalid Key",
+			key:     key[:16],
+			version: "5",
+			value:   encrypted,
+			wantErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decrypt(tt.key, "api-key", tt.version, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Incorrect value. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKeyFromFile(t *testing.T) {
+	s, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+
+	err = os.WriteFile(path, []byte(s+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	key, err := KeyFromFile(path)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if len(key) != KeySize {
+		t.Errorf("Incorrect key size. Want %d, got %d", KeySize, len(key))
+	}
+}
+
+func TestFileDelete(t *testing.T) {
+	key := newTestKey(t)
+
+	f := make(File)
+
+	err := f.Set(key, "api-key", "5", []byte("secret"))
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	f.Delete("api-key", "5")
+
+	if _, ok := f["api-key"]; ok {
+		t.Errorf("Incorrect secrets. Want %q removed, got %v", "api-key", f)
+	}
+}
@@ -7,8 +7,10 @@ package secretly
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 // GetSecretFunc gets the secret from the secret manager.
@@ -16,6 +18,16 @@ import (
 // just ignore the version parameter.
 type GetSecretFunc func(ctx context.Context, name, version string) ([]byte, error)
 
+// Decoder is implemented by types that decode their own secret content.
+// Fields of types implementing Decoder, [encoding.TextUnmarshaler]
+// or [encoding.BinaryUnmarshaler] are set by the type itself,
+// in that order of preference.
+// The content passed to them is the field's own copy,
+// so it can be kept, and zeroed, without affecting other fields.
+type Decoder interface {
+	DecodeSecret(b []byte) error
+}
+
 // Process interprets the provided specification,
 // resolving the described secrets
 // with the provided secret management Client.
@@ -25,26 +37,16 @@ func Process(ctx context.Context, spec any, getSecret GetSecretFunc, opts ...Pro
 		return fmt.Errorf("processing: %w", err)
 	}
 
+	p := processor{fields: fields}
+
 	for _, opt := range opts {
-		err := opt(fields)
+		err := opt(&p)
 		if err != nil {
 			return err
 		}
 	}
 
-	for _, field := range fields {
-		b, err := getSecret(ctx, field.SecretName(), field.secretVersion)
-		if err != nil {
-			return fmt.Errorf("getting secret: secret %q version %q: %w", field.SecretName(), field.secretVersion, err)
-		}
-
-		err = field.Set(b)
-		if err != nil {
-			return fmt.Errorf("setting field: %s: %w", field.Name(), err)
-		}
-	}
-
-	return nil
+	return p.process(ctx, getSecret)
 }
 
 // Process interprets the provided specification,
@@ -56,6 +58,138 @@ func MustProcess(ctx context.Context, spec any, getSecret GetSecretFunc, opts ..
 	}
 }
 
+// processor resolves the secrets described by its fields,
+// applying the settings configured by the [ProcessOption]s.
+type processor struct {
+	fields        fields
+	concurrency   int
+	collectErrors bool
+}
+
+// secretID identifies a specific version of a secret.
+type secretID struct {
+	name    string
+	version string
+}
+
+// process resolves and sets each of the processor's fields.
+func (p *processor) process(ctx context.Context, getSecret GetSecretFunc) error {
+	if p.concurrency > 1 {
+		return p.processConcurrently(ctx, getSecret)
+	}
+
+	var errs []error
+
+	for i := range p.fields {
+		field := &p.fields[i]
+
+		b, err := field.getSecret(ctx, getSecret)
+
+		err = field.resolve(b, err)
+		if err != nil {
+			if !p.collectErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// processConcurrently resolves each distinct secret referenced by the processor's
+// fields using at most p.concurrency concurrent calls to getSecret. The fields are
+// only set once all secrets have been resolved. Unless errors are being collected,
+// the first error, other than [ErrSecretNotFound], cancels the context passed to
+// any outstanding calls.
+func (p *processor) processConcurrently(ctx context.Context, getSecret GetSecretFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Deduplicate the secrets, remembering the first field referencing each
+	// so its cache is consulted when resolving it.
+	indexes := make(map[secretID]int, len(p.fields))
+	firsts := make([]int, 0, len(p.fields))
+
+	for i, field := range p.fields {
+		id := secretID{name: field.SecretName(), version: field.secretVersion}
+		if _, ok := indexes[id]; !ok {
+			indexes[id] = len(firsts)
+			firsts = append(firsts, i)
+		}
+	}
+
+	var (
+		contents  = make([][]byte, len(firsts))
+		fetchErrs = make([]error, len(firsts))
+		sem       = make(chan struct{}, p.concurrency)
+		wg        sync.WaitGroup
+		once      sync.Once
+		firstErr  error
+	)
+
+dispatch:
+	for i, fieldIndex := range firsts {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(i int, f *field) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			b, err := f.getSecret(ctx, getSecret)
+			if err != nil {
+				fetchErrs[i] = err
+
+				// Missing secrets may be handled by the fields' defaults,
+				// so are reported once the fields are set.
+				if !p.collectErrors && !errors.Is(err, ErrSecretNotFound) {
+					once.Do(func() {
+						firstErr = f.newError(err)
+						cancel()
+					})
+				}
+				return
+			}
+
+			contents[i] = b
+		}(i, &p.fields[fieldIndex])
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("getting secrets: %w", err)
+	}
+
+	var errs []error
+
+	for i := range p.fields {
+		field := &p.fields[i]
+		index := indexes[secretID{name: field.SecretName(), version: field.secretVersion}]
+
+		err := field.resolve(contents[index], fetchErrs[index])
+		if err != nil {
+			if !p.collectErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // processSpec interprets the provided specification,
 // returning a slice of fields referencing the specification's fields.
 // opts can be provided to add additional processing to the fields,
@@ -77,7 +211,7 @@ func processSpec(spec any) (fields, error) {
 
 	specType := specValue.Type()
 
-	fields, err := processStruct(specValue, specType)
+	fields, err := processStruct(specValue, specType, specType.Name())
 	if err != nil {
 		return nil, fmt.Errorf("processing: %w", err)
 	}
@@ -87,40 +221,63 @@ func processSpec(spec any) (fields, error) {
 
 // processStruct recursively processes the struct, specValue,
 // returning a slice of its fields.
-func processStruct(specValue reflect.Value, specType reflect.Type) (fields, error) {
+// path is the Go path to the struct, e.g. "Config.Database",
+// and is used to record the path to each of its fields.
+//
+// Every struct tag error is reported, joined into a single error.
+func processStruct(specValue reflect.Value, specType reflect.Type, path string) (fields, error) {
+	var errs []error
+
 	fields := make(fields, 0, specValue.NumField())
 
 	for i := 0; i < specValue.NumField(); i++ {
 		fValue, fStructField := specValue.Field(i), specType.Field(i)
+		fPath := joinPath(path, fStructField.Name)
 
 		// Get the ignored value, setting it to false if not explicitly set
 		ignored, _, err := parseOptionalStructTagKey[bool](fStructField, tagIgnored)
 		if err != nil {
-			return nil, StructTagError{
-				Name: fStructField.Name,
+			errs = append(errs, StructTagError{
+				Name: fPath,
 				Key:  tagIgnored,
 				Err:  err,
-			}
+			})
+			continue
 		}
 
 		if ignored || !fValue.CanSet() {
 			continue
 		}
 
+		// Types that decode themselves, or are unmarshalled from an entire
+		// secret, are processed as a single field, rather than recursing into them
+		if isDecodable(fStructField.Type) || isWholeSecret(fStructField) {
+			field, err := newField(fValue, fStructField, fPath)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			fields = append(fields, field)
+
+			continue
+		}
+
 		switch fStructField.Type.Kind() {
-		case reflect.Interface | reflect.Array | reflect.Slice | reflect.Map:
+		case reflect.Interface:
 			// ignore these types
 		case reflect.Struct:
-			fs, err := processStruct(fValue, fStructField.Type)
+			fs, err := processStruct(fValue, fStructField.Type, fPath)
 			if err != nil {
-				return nil, err
+				errs = append(errs, err)
+				continue
 			}
 			fields = append(fields, fs...)
 		case reflect.Pointer:
 			for fValue.Kind() == reflect.Pointer {
 				if fValue.IsNil() {
-					if fValue.Type().Elem().Kind() != reflect.Struct {
-						// value other than struct
+					if fValue.Type().Elem().Kind() != reflect.Struct || isDecodable(fValue.Type().Elem()) {
+						// value other than struct, or a struct that decodes itself
 						break
 					}
 					// value is a struct, initialize it
@@ -129,10 +286,11 @@ func processStruct(specValue reflect.Value, specType reflect.Type) (fields, erro
 				fValue = fValue.Elem()
 			}
 
-			if fValue.Kind() == reflect.Struct {
-				subFields, err := processStruct(fValue, fValue.Type())
+			if fValue.Kind() == reflect.Struct && !isDecodable(fValue.Type()) {
+				subFields, err := processStruct(fValue, fValue.Type(), fPath)
 				if err != nil {
-					return nil, err
+					errs = append(errs, err)
+					continue
 				}
 
 				fields = append(fields, subFields...)
@@ -142,13 +300,28 @@ func processStruct(specValue reflect.Value, specType reflect.Type) (fields, erro
 
 			fallthrough
 		default:
-			field, err := newField(fValue, fStructField)
+			field, err := newField(fValue, fStructField, fPath)
 			if err != nil {
-				return nil, err
+				errs = append(errs, err)
+				continue
 			}
 
 			fields = append(fields, field)
 		}
 	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
 	return fields, nil
 }
+
+// joinPath joins the Go path to a struct with the name of one of its fields.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
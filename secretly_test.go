@@ -3,8 +3,13 @@ package secretly
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var errGetSecret = errors.New("get secret error")
@@ -224,3 +229,499 @@ func TestProcessWithPatch(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessWithCache(t *testing.T) {
+	type specification struct {
+		Username string `type:"yaml" name:"db-credentials" key:"username"`
+		Password string `type:"yaml" name:"db-credentials" key:"password"`
+		Host     string `type:"yaml" name:"db-credentials" key:"host" version:"1"`
+	}
+
+	var secretsMap = map[string]map[string]string{
+		"db-credentials": {
+			"0": "username: user\npassword: pass\nhost: old-host",
+			"1": "username: user\npassword: pass\nhost: new-host",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []ProcessOption
+		wantCalls int
+	}{
+		{
+			name:      "Without Cache",
+			opts:      nil,
+			wantCalls: 3,
+		},
+		{
+			name:      "With Cache",
+			opts:      []ProcessOption{WithCache()},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+				calls++
+				return getSecretFromMapManager(secretsMap, nil)(ctx, name, version)
+			}
+
+			var spec specification
+			err := Process(context.Background(), &spec, getSecret, tt.opts...)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			want := specification{Username: "user", Password: "pass", Host: "new-host"}
+			if !reflect.DeepEqual(want, spec) {
+				t.Fatalf("Incorrect specification. Want %v, got %v", want, spec)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("Incorrect number of GetSecretFunc calls. Want %d, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestProcessWithCacheReusedOption(t *testing.T) {
+	type specification struct {
+		Password string `name:"db-password"`
+	}
+
+	var secretsMap = map[string]map[string]string{
+		"db-password": {
+			"0": "pass",
+		},
+	}
+
+	var calls int
+	getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+		calls++
+		return getSecretFromMapManager(secretsMap, nil)(ctx, name, version)
+	}
+
+	// Each Process call gets its own cache, even when the option is reused
+	opt := WithCache()
+
+	for i := 0; i < 2; i++ {
+		var spec specification
+		err := Process(context.Background(), &spec, getSecret, opt)
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Incorrect number of GetSecretFunc calls. Want %d, got %d", 2, calls)
+	}
+}
+
+func TestProcessWithSharedCache(t *testing.T) {
+	type specification struct {
+		Username string `type:"yaml" name:"db-credentials" key:"username"`
+		Password string `type:"yaml" name:"db-credentials" key:"password"`
+	}
+
+	var secretsMap = map[string]map[string]string{
+		"db-credentials": {
+			"0": "username: user\npassword: pass",
+		},
+	}
+
+	var calls int
+	getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+		calls++
+		return getSecretFromMapManager(secretsMap, nil)(ctx, name, version)
+	}
+
+	cache := NewCache()
+
+	for i := 0; i < 2; i++ {
+		var spec specification
+		err := Process(context.Background(), &spec, getSecret, WithSharedCache(cache))
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+
+		want := specification{Username: "user", Password: "pass"}
+		if !reflect.DeepEqual(want, spec) {
+			t.Fatalf("Incorrect specification. Want %v, got %v", want, spec)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Incorrect number of GetSecretFunc calls. Want %d, got %d", 1, calls)
+	}
+
+	want := CacheStats{Hits: 3, Misses: 1, Entries: 1, Bytes: len(secretsMap["db-credentials"]["0"])}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Incorrect cache stats. Want %+v, got %+v", want, got)
+	}
+}
+
+func TestProcessWithConcurrency(t *testing.T) {
+	type specification struct {
+		Username string `type:"yaml" name:"db-credentials" key:"username"`
+		Password string `type:"yaml" name:"db-credentials" key:"password"`
+		APIKey   string `name:"api-key"`
+		Token    string `name:"token"`
+	}
+
+	var secretsMap = map[string]map[string]string{
+		"db-credentials": {
+			"0": "username: user\npassword: pass",
+		},
+		"api-key": {
+			"0": "key",
+		},
+		"token": {
+			"0": "token",
+		},
+	}
+
+	t.Run("Deduplicates Secrets", func(t *testing.T) {
+		var calls int32
+		getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+			atomic.AddInt32(&calls, 1)
+			return getSecretFromMapManager(secretsMap, nil)(ctx, name, version)
+		}
+
+		var spec specification
+		err := Process(context.Background(), &spec, getSecret, WithConcurrency(2))
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+
+		want := specification{Username: "user", Password: "pass", APIKey: "key", Token: "token"}
+		if !reflect.DeepEqual(want, spec) {
+			t.Fatalf("Incorrect specification. Want %v, got %v", want, spec)
+		}
+
+		if calls != 3 {
+			t.Errorf("Incorrect number of GetSecretFunc calls. Want %d, got %d", 3, calls)
+		}
+	})
+
+	t.Run("GetSecret Error", func(t *testing.T) {
+		getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+			if name == "api-key" {
+				return nil, errGetSecret
+			}
+
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		var spec specification
+		err := Process(context.Background(), &spec, getSecret, WithConcurrency(3))
+		if !errors.Is(err, errGetSecret) {
+			t.Fatalf("Incorrect error. Want %v, got %v", errGetSecret, err)
+		}
+
+		if !reflect.DeepEqual(specification{}, spec) {
+			t.Fatalf("Incorrect specification. Want %v, got %v", specification{}, spec)
+		}
+	})
+}
+
+func TestProcessWithCollectErrors(t *testing.T) {
+	type specification struct {
+		Field        string
+		MissingKey   string `type:"json" name:"JSONField" key:"Missing"`
+		InvalidJSON  string `type:"json" name:"InvalidJSONField" key:"Field"`
+		FailingField string
+	}
+
+	var secretsMap = map[string]map[string]string{
+		"Field": {
+			"0": "field secret",
+		},
+		"JSONField": {
+			"0": `{"Field": "json field secret"}`,
+		},
+		"InvalidJSONField": {
+			"0": `not json`,
+		},
+	}
+
+	getSecret := func(ctx context.Context, name, version string) ([]byte, error) {
+		if name == "FailingField" {
+			return nil, errGetSecret
+		}
+		return getSecretFromMapManager(secretsMap, nil)(ctx, name, version)
+	}
+
+	for _, opts := range [][]ProcessOption{
+		{WithCollectErrors()},
+		{WithCollectErrors(), WithConcurrency(2)},
+	} {
+		var spec specification
+		err := Process(context.Background(), &spec, getSecret, opts...)
+
+		for _, wantErr := range []error{ErrSecretMissingKey, ErrInvalidJSONSecret, errGetSecret} {
+			if !errors.Is(err, wantErr) {
+				t.Errorf("Incorrect error. Want %v, got %v", wantErr, err)
+			}
+		}
+
+		if spec.Field != "field secret" {
+			t.Errorf("Incorrect Field. Want %v, got %v", "field secret", spec.Field)
+		}
+	}
+}
+
+func TestProcessStructTagErrors(t *testing.T) {
+	type specification struct {
+		InvalidType    string `type:"xml"`
+		InvalidIgnored string `ignored:"maybe"`
+	}
+
+	var spec specification
+	err := Process(context.Background(), &spec, getSecretFromMapManager(nil, nil))
+
+	var structTagErr StructTagError
+	if !errors.As(err, &structTagErr) {
+		t.Fatalf("Incorrect error. Want %T, got %v", structTagErr, err)
+	}
+
+	if !errors.Is(err, ErrInvalidSecretType) {
+		t.Errorf("Incorrect error. Want %v, got %v", ErrInvalidSecretType, err)
+	}
+
+	if !strings.Contains(err.Error(), tagIgnored) {
+		t.Errorf("Incorrect error. Want error for %q, got %v", tagIgnored, err)
+	}
+}
+
+func TestProcessFieldError(t *testing.T) {
+	type Database struct {
+		Password string `type:"json" name:"db-credentials" key:"password" version:"2"`
+	}
+
+	type Config struct {
+		Database *Database
+	}
+
+	getSecret := getSecretFromMapManager(map[string]map[string]string{
+		"db-credentials": {
+			"2": `{"username": "user"}`,
+		},
+	}, nil)
+
+	var config Config
+	err := Process(context.Background(), &config, getSecret)
+
+	var fieldErr FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Incorrect error. Want %T, got %v", fieldErr, err)
+	}
+
+	want := FieldError{
+		Path:          "Config.Database.Password",
+		SecretName:    "db-credentials",
+		SecretVersion: "2",
+		SecretKey:     "password",
+		SecretType:    JSON,
+		Err:           fieldErr.Err,
+	}
+	if !reflect.DeepEqual(want, fieldErr) {
+		t.Errorf("Incorrect FieldError. Want %+v, got %+v", want, fieldErr)
+	}
+
+	if !errors.Is(err, ErrSecretMissingKey) {
+		t.Errorf("Incorrect error. Want %v, got %v", ErrSecretMissingKey, err)
+	}
+}
+
+func TestProcessDecodableStructs(t *testing.T) {
+	type specification struct {
+		Endpoint  *url.URL
+		ExpiresAt time.Time
+	}
+
+	getSecret := getSecretFromMapManager(map[string]map[string]string{
+		"Endpoint": {
+			"0": "https://example.com",
+		},
+		"ExpiresAt": {
+			"0": "2023-01-02T03:04:05Z",
+		},
+	}, nil)
+
+	var spec specification
+	err := Process(context.Background(), &spec, getSecret)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	want := specification{
+		Endpoint:  &url.URL{Scheme: "https", Host: "example.com"},
+		ExpiresAt: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(want, spec) {
+		t.Fatalf("Incorrect specification. Want %v, got %v", want, spec)
+	}
+}
+
+func TestProcessWholeSecret(t *testing.T) {
+	type ServiceAccount struct {
+		ProjectID string   `json:"project_id" yaml:"project_id"`
+		Scopes    []string `json:"scopes" yaml:"scopes"`
+		Port      int      `json:"port" yaml:"port"`
+	}
+
+	type specification struct {
+		JSONAccount ServiceAccount    `type:"json" name:"json-account" key:"-"`
+		YAMLAccount *ServiceAccount   `type:"yaml" name:"yaml-account" key:"-"`
+		Labels      map[string]string `type:"json" name:"labels" key:"-"`
+	}
+
+	secretsMap := map[string]map[string]string{
+		"json-account": {
+			"0": `{"project_id": "project", "scopes": ["a", "b"], "port": 8080}`,
+		},
+		"yaml-account": {
+			"0": "project_id: project\nscopes: [a, b]\nport: 8080",
+		},
+		"labels": {
+			"0": `{"team": "platform"}`,
+		},
+	}
+
+	var spec specification
+	err := Process(context.Background(), &spec, getSecretFromMapManager(secretsMap, nil))
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	account := ServiceAccount{ProjectID: "project", Scopes: []string{"a", "b"}, Port: 8080}
+	want := specification{
+		JSONAccount: account,
+		YAMLAccount: &account,
+		Labels:      map[string]string{"team": "platform"},
+	}
+	if !reflect.DeepEqual(want, spec) {
+		t.Fatalf("Incorrect specification. Want %+v, got %+v", want, spec)
+	}
+
+	secretsMap["json-account"]["0"] = `{"port": "not a number"}`
+
+	err = Process(context.Background(), &spec, getSecretFromMapManager(secretsMap, nil))
+	if !errors.Is(err, ErrInvalidJSONSecret) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrInvalidJSONSecret, err)
+	}
+}
+
+// getSecretFromMapManagerNotFound returns a GetSecretFunc that wraps
+// ErrSecretNotFound when the secret or version is missing from secrets.
+func getSecretFromMapManagerNotFound(secrets map[string]map[string]string) GetSecretFunc {
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		secret, ok := secrets[name][version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+
+		return []byte(secret), nil
+	}
+}
+
+func TestProcessWithDefaults(t *testing.T) {
+	type specification struct {
+		Present    string `default:"unused"`
+		Missing    string `default:"default"`
+		MissingInt int    `default:"10"`
+		MissingKey string `type:"json" name:"JSON" key:"Missing" default:"default key"`
+		Patched    string
+		Required   string
+	}
+
+	secretsMap := map[string]map[string]string{
+		"Present": {
+			"0": "present",
+		},
+		"JSON": {
+			"0": `{"Present": "present"}`,
+		},
+	}
+
+	for _, opts := range [][]ProcessOption{
+		{WithPatch([]byte(`Patched: {default: patched}`))},
+		{WithPatch([]byte(`Patched: {default: patched}`)), WithConcurrency(4)},
+	} {
+		var spec specification
+		err := Process(context.Background(), &spec, getSecretFromMapManagerNotFound(secretsMap), opts...)
+
+		var fieldErr FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "specification.Required" {
+			t.Fatalf("Incorrect error. Want %v for %q, got %v", ErrSecretNotFound, "specification.Required", err)
+		}
+
+		if !errors.Is(err, ErrSecretNotFound) {
+			t.Fatalf("Incorrect error. Want %v, got %v", ErrSecretNotFound, err)
+		}
+
+		want := specification{
+			Present:    "present",
+			Missing:    "default",
+			MissingInt: 10,
+			MissingKey: "default key",
+			Patched:    "patched",
+		}
+		if !reflect.DeepEqual(want, spec) {
+			t.Errorf("Incorrect specification. Want %+v, got %+v", want, spec)
+		}
+	}
+}
+
+func TestProcessOptionalFields(t *testing.T) {
+	type specification struct {
+		Optional    string `optional:"true"`
+		NotRequired int    `required:"false"`
+		OptionalKey string `type:"json" name:"JSON" key:"Missing" optional:"true"`
+		Patched     string
+		Present     string `optional:"true"`
+	}
+
+	secretsMap := map[string]map[string]string{
+		"JSON": {
+			"0": `{"Present": "present"}`,
+		},
+		"Present": {
+			"0": "present",
+		},
+	}
+
+	var spec specification
+	err := Process(context.Background(), &spec, getSecretFromMapManagerNotFound(secretsMap),
+		WithPatch([]byte(`Patched: {optional: true}`)))
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	want := specification{Present: "present"}
+	if !reflect.DeepEqual(want, spec) {
+		t.Errorf("Incorrect specification. Want %+v, got %+v", want, spec)
+	}
+
+	// Errors other than missing secrets or keys are still reported
+	err = Process(context.Background(), &spec, getSecretFromMapManager(nil, errGetSecret))
+	if !errors.Is(err, errGetSecret) {
+		t.Fatalf("Incorrect error. Want %v, got %v", errGetSecret, err)
+	}
+}
+
+func TestProcessConflictingOptionalTags(t *testing.T) {
+	type specification struct {
+		Field string `optional:"true" required:"true"`
+	}
+
+	var spec specification
+	err := Process(context.Background(), &spec, getSecretFromMapManager(nil, nil))
+	if !errors.Is(err, ErrConflictingTags) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrConflictingTags, err)
+	}
+}
@@ -0,0 +1,120 @@
+package sops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Environment variables read by [AgeKeySourceFromEnv], matching SOPS.
+const (
+	envAgeKey     = "SOPS_AGE_KEY"
+	envAgeKeyFile = "SOPS_AGE_KEY_FILE"
+)
+
+var (
+	ErrMissingAgeIdentities = errors.New("sops: missing age identities")
+	ErrMissingAgeRecipients = errors.New("sops: file has no age recipients")
+)
+
+// AgeKeySource returns a [KeySource] which decrypts the file's data key
+// with the age identities, trying each of the file's age recipients in turn.
+func AgeKeySource(identities ...age.Identity) KeySource {
+	return func(ctx context.Context, m Metadata) ([]byte, error) {
+		if len(identities) == 0 {
+			return nil, ErrMissingAgeIdentities
+		}
+
+		if len(m.Age) == 0 {
+			return nil, ErrMissingAgeRecipients
+		}
+
+		errs := make([]error, 0, len(m.Age))
+
+		for _, recipient := range m.Age {
+			key, err := decryptAgeKey(recipient.Enc, identities)
+			if err == nil {
+				return key, nil
+			}
+
+			errs = append(errs, fmt.Errorf("age recipient %q: %w", recipient.Recipient, err))
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// AgeKeySourceFromEnv returns an [AgeKeySource] using the age identities
+// SOPS uses: those in $SOPS_AGE_KEY, and in the file $SOPS_AGE_KEY_FILE,
+// or, if neither is set, in SOPS's default keys file, "sops/age/keys.txt"
+// in the user's config directory.
+func AgeKeySourceFromEnv() (KeySource, error) {
+	var identities []age.Identity
+
+	if keys, ok := os.LookupEnv(envAgeKey); ok {
+		ids, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("parsing $%s: %w", envAgeKey, err)
+		}
+
+		identities = append(identities, ids...)
+	}
+
+	path, ok := os.LookupEnv(envAgeKeyFile)
+	if !ok && len(identities) == 0 {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMissingAgeIdentities, err)
+		}
+
+		path = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+
+	if path != "" {
+		ids, err := readAgeIdentities(path)
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		return nil, ErrMissingAgeIdentities
+	}
+
+	return AgeKeySource(identities...), nil
+}
+
+// readAgeIdentities reads the age identities in the keys file at path.
+func readAgeIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading age keys file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing age keys file %q: %w", path, err)
+	}
+
+	return identities, nil
+}
+
+// decryptAgeKey decrypts the armored, age encrypted data key, enc, with the identities.
+func decryptAgeKey(enc string, identities []age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(enc)), identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
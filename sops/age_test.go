@@ -0,0 +1,174 @@
+package sops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// encryptAgeKey encrypts the data key, key, to the age recipient, armored as SOPS stores it.
+func encryptAgeKey(t *testing.T, key []byte, recipient age.Recipient) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	a := armor.NewWriter(&buf)
+
+	w, err := age.Encrypt(a, recipient)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if _, err := w.Write(key); err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	return buf.String()
+}
+
+// newTestIdentity returns a new age identity.
+func newTestIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	return identity
+}
+
+func TestAgeKeySource(t *testing.T) {
+	identity, other := newTestIdentity(t), newTestIdentity(t)
+
+	metadata := Metadata{Age: []AgeRecipient{
+		{Recipient: other.Recipient().String(), Enc: encryptAgeKey(t, testKey, other.Recipient())},
+		{Recipient: identity.Recipient().String(), Enc: encryptAgeKey(t, testKey, identity.Recipient())},
+	}}
+
+	tests := []struct {
+		name       string
+		identities []age.Identity
+		metadata   Metadata
+		want       []byte
+		wantErr    error
+	}{
+		{
+			name:       "Second Recipient",
+			identities: []age.Identity{identity},
+			metadata:   metadata,
+			want:       testKey,
+		},
+		{
+			name:       "No Matching Identity",
+			identities: []age.Identity{newTestIdentity(t)},
+			metadata:   metadata,
+			wantErr:    &age.NoIdentityMatchError{},
+		},
+		{
+			name:       "No Recipients",
+			identities: []age.Identity{identity},
+			metadata:   Metadata{},
+			wantErr:    ErrMissingAgeRecipients,
+		},
+		{
+			name:     "No Identities",
+			metadata: metadata,
+			wantErr:  ErrMissingAgeIdentities,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AgeKeySource(tt.identities...)(context.Background(), tt.metadata)
+
+			var noMatch *age.NoIdentityMatchError
+			if errors.As(tt.wantErr, &noMatch) {
+				if !errors.As(err, &noMatch) {
+					t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Incorrect data key. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAgeKeySourceFromEnv(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	metadata := Metadata{Age: []AgeRecipient{
+		{Recipient: identity.Recipient().String(), Enc: encryptAgeKey(t, testKey, identity.Recipient())},
+	}}
+
+	path := filepath.Join(t.TempDir(), "keys.txt")
+
+	err := os.WriteFile(path, []byte("# created: test\n"+identity.String()+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{
+			name: "Key",
+			env:  map[string]string{envAgeKey: identity.String()},
+		},
+		{
+			name: "Key File",
+			env:  map[string]string{envAgeKeyFile: path},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			keySource, err := AgeKeySourceFromEnv()
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			got, err := keySource(context.Background(), metadata)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			if !bytes.Equal(got, testKey) {
+				t.Errorf("Incorrect data key. Want %q, got %q", testKey, got)
+			}
+		})
+	}
+
+	t.Run("Missing Key File", func(t *testing.T) {
+		t.Setenv(envAgeKeyFile, filepath.Join(t.TempDir(), "missing.txt"))
+
+		_, err := AgeKeySourceFromEnv()
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Incorrect error. Want %v, got %v", fs.ErrNotExist, err)
+		}
+	})
+}
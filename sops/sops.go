@@ -0,0 +1,524 @@
+// Package sops provides a [secretly.GetSecretFunc] for SOPS encrypted
+// YAML and JSON files, exposing each top-level key as a secret.
+//
+// The file's data key is provided by a [KeySource]. Files encrypted with age
+// are decrypted with [AgeKeySource], or [AgeKeySourceFromEnv] which reads the
+// same age identities as SOPS. Files encrypted with other key management
+// services can be used with a custom [KeySource], decrypting the data key
+// with the file's [Metadata], or by providing the data key with [StaticKey].
+package sops
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jack-mcveigh/secretly"
+	"gopkg.in/yaml.v3"
+)
+
+// metadataKey is the top-level key holding the file's SOPS metadata.
+const metadataKey = "sops"
+
+var (
+	ErrMissingKeySource = errors.New("sops: missing key source")
+	ErrInvalidFile      = errors.New("sops: invalid file")
+	ErrInvalidValue     = errors.New("sops: invalid encrypted value")
+	ErrDecrypt          = errors.New("sops: decrypting value")
+	ErrMACMismatch      = errors.New("sops: mac mismatch")
+)
+
+// encryptedValue matches values encrypted by SOPS, capturing their
+// data, iv, tag and type.
+var encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:(.*)\]$`)
+
+type (
+	// KeySource returns the data key used to encrypt the values
+	// of the file described by m.
+	KeySource func(ctx context.Context, m Metadata) ([]byte, error)
+
+	// Metadata is the SOPS metadata stored in the file's "sops" key.
+	Metadata struct {
+		LastModified      string         `yaml:"lastmodified"`
+		MAC               string         `yaml:"mac"`
+		Age               []AgeRecipient `yaml:"age"`
+		UnencryptedSuffix string         `yaml:"unencrypted_suffix"`
+		EncryptedSuffix   string         `yaml:"encrypted_suffix"`
+		UnencryptedRegex  string         `yaml:"unencrypted_regex"`
+		EncryptedRegex    string         `yaml:"encrypted_regex"`
+		MACOnlyEncrypted  bool           `yaml:"mac_only_encrypted"`
+		Version           string         `yaml:"version"`
+	}
+
+	// AgeRecipient is an age recipient the data key is encrypted for,
+	// with Enc holding the age encrypted data key.
+	AgeRecipient struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	}
+
+	// provider gets secrets from a SOPS file,
+	// decrypting it the first time a secret is requested.
+	provider struct {
+		keySource KeySource
+		json      bool
+		root      *yaml.Node
+		metadata  Metadata
+
+		mu      sync.Mutex
+		secrets map[string][]byte
+	}
+
+	// decrypter decrypts a SOPS file's tree in place,
+	// computing its MAC as it goes.
+	decrypter struct {
+		key              []byte
+		metadata         Metadata
+		unencryptedRegex *regexp.Regexp
+		encryptedRegex   *regexp.Regexp
+		mac              hash.Hash
+	}
+)
+
+// StaticKey returns a [KeySource] which always returns the data key, key.
+func StaticKey(key []byte) KeySource {
+	return func(ctx context.Context, m Metadata) ([]byte, error) {
+		return key, nil
+	}
+}
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets
+// from the SOPS file at path, decoded as JSON or YAML based on its extension.
+// The file is read when the provider is created, and decrypted,
+// verifying its MAC, the first time a secret is requested.
+//
+// Each top-level key is a secret. Scalar values are returned as text,
+// and objects and arrays are encoded in the file's format,
+// for use with the "json" and "yaml" types.
+// SOPS files are not versioned, so only [secretly.DefaultVersion] is accepted.
+// If the key does not exist, an error wrapping [secretly.ErrSecretNotFound] is returned.
+func Provider(path string, keySource KeySource) (secretly.GetSecretFunc, error) {
+	if keySource == nil {
+		return nil, ErrMissingKeySource
+	}
+
+	p := &provider{keySource: keySource}
+
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		p.json = true
+	case ".yaml", ".yml":
+	default:
+		return nil, fmt.Errorf("%w: %s", secretly.ErrInvalidFileType, ext)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sops file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both formats are decoded as YAML,
+	// preserving the key order the MAC is computed in.
+	var doc yaml.Node
+
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: not an object", ErrInvalidFile)
+	}
+
+	p.root = doc.Content[0]
+
+	metadata, ok := lookup(p.root, metadataKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q metadata", ErrInvalidFile, metadataKey)
+	}
+
+	err = metadata.Decode(&p.metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding metadata: %v", ErrInvalidFile, err)
+	}
+
+	return p.getSecret, nil
+}
+
+// getSecret gets the top-level key, name, implementing [secretly.GetSecretFunc].
+func (p *provider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	if version != secretly.DefaultVersion {
+		return nil, fmt.Errorf("%w: sops files are not versioned: %q", secretly.ErrInvalidSecretVersion, version)
+	}
+
+	secrets, err := p.decrypt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := secrets[name]
+	if !ok || name == metadataKey {
+		return nil, fmt.Errorf("%w: key %q", secretly.ErrSecretNotFound, name)
+	}
+
+	return b, nil
+}
+
+// decrypt decrypts the file, verifying its MAC,
+// and returns its top-level keys encoded as secrets.
+// The secrets are kept for subsequent calls once successfully decrypted.
+func (p *provider) decrypt(ctx context.Context) (map[string][]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.secrets != nil {
+		return p.secrets, nil
+	}
+
+	key, err := p.keySource(ctx, p.metadata)
+	if err != nil {
+		return nil, fmt.Errorf("getting data key: %w", err)
+	}
+
+	d, err := newDecrypter(key, p.metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt a copy, so a failed attempt can be retried.
+	root := copyNode(p.root)
+
+	err = d.decryptTree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string][]byte, len(root.Content)/2)
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name, value := root.Content[i].Value, root.Content[i+1]
+		if name == metadataKey {
+			continue
+		}
+
+		b, err := p.encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding key %q: %w", name, err)
+		}
+
+		secrets[name] = b
+	}
+
+	p.secrets = secrets
+
+	return secrets, nil
+}
+
+// encode encodes the decrypted value as a secret,
+// returning scalars as text and other values in the file's format.
+func (p *provider) encode(n *yaml.Node) ([]byte, error) {
+	if n.Kind == yaml.ScalarNode {
+		return []byte(n.Value), nil
+	}
+
+	if !p.json {
+		return yaml.Marshal(n)
+	}
+
+	var v any
+
+	err := n.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// newDecrypter returns a decrypter for the file described by m,
+// encrypted with the data key, key.
+func newDecrypter(key []byte, m Metadata) (*decrypter, error) {
+	d := &decrypter{key: key, metadata: m, mac: sha512.New()}
+
+	var err error
+
+	if m.UnencryptedRegex != "" {
+		d.unencryptedRegex, err = regexp.Compile(m.UnencryptedRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unencrypted_regex: %v", ErrInvalidFile, err)
+		}
+	}
+
+	if m.EncryptedRegex != "" {
+		d.encryptedRegex, err = regexp.Compile(m.EncryptedRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encrypted_regex: %v", ErrInvalidFile, err)
+		}
+	}
+
+	return d, nil
+}
+
+// decryptTree decrypts the values of the file's root object in place,
+// skipping the metadata, and verifies the file's MAC.
+func (d *decrypter) decryptTree(root *yaml.Node) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == metadataKey {
+			continue
+		}
+
+		err := d.walk(root.Content[i+1], []string{root.Content[i].Value})
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.metadata.MAC == "" {
+		return fmt.Errorf("%w: missing mac", ErrInvalidFile)
+	}
+
+	want, _, err := d.decryptValue(d.metadata.MAC, d.metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("decrypting mac: %w", err)
+	}
+
+	got := fmt.Sprintf("%X", d.mac.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return ErrMACMismatch
+	}
+
+	return nil
+}
+
+// walk recursively decrypts the node, n, at path.
+// Array elements share the path of their array, as they do in SOPS.
+func (d *decrypter) walk(n *yaml.Node, path []string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			// Limit the capacity so appending copies the path
+			err := d.walk(n.Content[i+1], append(path[:len(path):len(path)], n.Content[i].Value))
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			err := d.walk(c, path)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return d.decryptLeaf(n, path)
+	default:
+		return fmt.Errorf("%w: unsupported node at %q", ErrInvalidFile, strings.Join(path, "."))
+	}
+
+	return nil
+}
+
+// decryptLeaf decrypts the scalar, n, at path, if it is encrypted,
+// and adds its value to the MAC.
+func (d *decrypter) decryptLeaf(n *yaml.Node, path []string) error {
+	encrypted := d.isEncrypted(path)
+
+	if !encrypted {
+		if !d.metadata.MACOnlyEncrypted {
+			var v any
+
+			err := n.Decode(&v)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+			}
+
+			d.mac.Write(macBytes(v))
+		}
+
+		return nil
+	}
+
+	plaintext, typ, err := d.decryptValue(n.Value, strings.Join(path, ":")+":")
+	if err != nil {
+		return fmt.Errorf("key %q: %w", strings.Join(path, "."), err)
+	}
+
+	var v any
+
+	switch typ {
+	case "str", "bytes":
+		v, n.Tag = plaintext, "!!str"
+	case "int":
+		v, err = strconv.Atoi(plaintext)
+		n.Tag = "!!int"
+	case "float":
+		v, err = strconv.ParseFloat(plaintext, 64)
+		n.Tag = "!!float"
+	case "bool":
+		v, err = strconv.ParseBool(plaintext)
+		n.Tag = "!!bool"
+		plaintext = strings.ToLower(plaintext)
+	default:
+		err = fmt.Errorf("unknown type %q", typ)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: key %q: %v", ErrInvalidValue, strings.Join(path, "."), err)
+	}
+
+	n.Value, n.Style = plaintext, 0
+
+	d.mac.Write(macBytes(v))
+
+	return nil
+}
+
+// isEncrypted reports whether the value at path is encrypted,
+// based on the file's encryption rules.
+func (d *decrypter) isEncrypted(path []string) bool {
+	encrypted := true
+
+	if suffix := d.metadata.UnencryptedSuffix; suffix != "" {
+		for _, k := range path {
+			if strings.HasSuffix(k, suffix) {
+				encrypted = false
+				break
+			}
+		}
+	}
+
+	if suffix := d.metadata.EncryptedSuffix; suffix != "" {
+		encrypted = false
+		for _, k := range path {
+			if strings.HasSuffix(k, suffix) {
+				encrypted = true
+				break
+			}
+		}
+	}
+
+	if d.unencryptedRegex != nil {
+		for _, k := range path {
+			if d.unencryptedRegex.MatchString(k) {
+				encrypted = false
+				break
+			}
+		}
+	}
+
+	if d.encryptedRegex != nil {
+		encrypted = false
+		for _, k := range path {
+			if d.encryptedRegex.MatchString(k) {
+				encrypted = true
+				break
+			}
+		}
+	}
+
+	return encrypted
+}
+
+// decryptValue decrypts the SOPS encrypted value, authenticating
+// the additional data, returning the plaintext and its type.
+func (d *decrypter) decryptValue(value, additionalData string) (string, string, error) {
+	matches := encryptedValue.FindStringSubmatch(value)
+	if matches == nil {
+		return "", "", ErrInvalidValue
+	}
+
+	var parts [3][]byte
+
+	for i, s := range matches[1:4] {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+
+		parts[i] = b
+	}
+
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(d.key)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	if len(iv) == 0 {
+		return "", "", fmt.Errorf("%w: empty iv", ErrInvalidValue)
+	}
+
+	aead, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	plaintext, err := aead.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	return string(plaintext), matches[4], nil
+}
+
+// macBytes returns the bytes of the value, v, added to the MAC,
+// formatted the way SOPS formats them.
+func macBytes(v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case int:
+		return []byte(strconv.Itoa(v))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		// Booleans are title cased, matching the original Python implementation
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	case nil:
+		return nil
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// lookup returns the value of the mapping node's key.
+func lookup(n *yaml.Node, key string) (*yaml.Node, bool) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1], true
+		}
+	}
+
+	return nil, false
+}
+
+// copyNode returns a deep copy of the node, n.
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, child := range n.Content {
+			c.Content[i] = copyNode(child)
+		}
+	}
+
+	return &c
+}
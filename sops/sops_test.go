@@ -0,0 +1,342 @@
+package sops
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const testLastModified = "2024-01-02T03:04:05Z"
+
+// testKey is the data key used to encrypt the test files.
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+// encrypt encrypts the value the way SOPS does.
+func encrypt(t *testing.T, value, typ, additionalData string) string {
+	t.Helper()
+
+	block, err := aes.NewCipher(testKey)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	aead, err := cipher.NewGCMWithNonceSize(block, 32)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	iv := make([]byte, 32)
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	b := aead.Seal(nil, iv, []byte(value), []byte(additionalData))
+	data, tag := b[:len(b)-aead.Overhead()], b[len(b)-aead.Overhead():]
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag),
+		typ,
+	)
+}
+
+// writeTestFile writes a SOPS file, with the mac computed over macValues,
+// in the format of the file's extension.
+func writeTestFile(t *testing.T, name string, macValues ...string) string {
+	t.Helper()
+
+	mac := sha512.New()
+	for _, v := range macValues {
+		mac.Write([]byte(v))
+	}
+
+	values := map[string]string{
+		"password": encrypt(t, "hunter2", "str", "password:"),
+		"port":     encrypt(t, "5432", "int", "db:port:"),
+		"host":     encrypt(t, "db.internal", "str", "db:host:"),
+		"tls":      encrypt(t, "True", "bool", "db:tls:"),
+		"hosts":    encrypt(t, "a.internal", "str", "hosts:"),
+		"mac":      encrypt(t, fmt.Sprintf("%X", mac.Sum(nil)), "str", testLastModified),
+	}
+
+	var content string
+
+	switch filepath.Ext(name) {
+	case ".yaml":
+		content = fmt.Sprintf(`password: %s
+db:
+    host: %s
+    port: %s
+    tls: %s
+    user_unencrypted: admin
+hosts:
+    - %s
+sops:
+    lastmodified: "%s"
+    mac: %s
+    unencrypted_suffix: _unencrypted
+    version: 3.8.1
+`, values["password"], values["host"], values["port"], values["tls"], values["hosts"], testLastModified, values["mac"])
+	case ".json":
+		content = fmt.Sprintf(`{
+	"password": %q,
+	"db": {"host": %q, "port": %q, "tls": %q, "user_unencrypted": "admin"},
+	"hosts": [%q],
+	"sops": {"lastmodified": %q, "mac": %q, "unencrypted_suffix": "_unencrypted", "version": "3.8.1"}
+}`, values["password"], values["host"], values["port"], values["tls"], values["hosts"], testLastModified, values["mac"])
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	return path
+}
+
+// testMACValues are the test file's values, in order, as they are added to the MAC.
+var testMACValues = []string{"hunter2", "db.internal", "5432", "True", "admin", "a.internal"}
+
+func TestProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "YAML Scalar",
+			file:       "secrets.yaml",
+			secretName: "password",
+			version:    secretly.DefaultVersion,
+			want:       "hunter2",
+		},
+		{
+			name:       "YAML Object",
+			file:       "secrets.yaml",
+			secretName: "db",
+			version:    secretly.DefaultVersion,
+			want:       "host: db.internal\nport: 5432\ntls: true\nuser_unencrypted: admin\n",
+		},
+		{
+			name:       "JSON Object",
+			file:       "secrets.json",
+			secretName: "db",
+			version:    secretly.DefaultVersion,
+			want:       `{"host":"db.internal","port":5432,"tls":true,"user_unencrypted":"admin"}`,
+		},
+		{
+			name:       "JSON Array",
+			file:       "secrets.json",
+			secretName: "hosts",
+			version:    secretly.DefaultVersion,
+			want:       `["a.internal"]`,
+		},
+		{
+			name:       "Missing Key",
+			file:       "secrets.yaml",
+			secretName: "api-key",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Metadata",
+			file:       "secrets.yaml",
+			secretName: "sops",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "Version",
+			file:       "secrets.yaml",
+			secretName: "password",
+			version:    "5",
+			wantErr:    secretly.ErrInvalidSecretVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getSecret, err := Provider(writeTestFile(t, tt.file, testMACValues...), StaticKey(testKey))
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderDecryptErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		macValues []string
+		key       []byte
+		wantErr   error
+	}{
+		{
+			name:      "MAC Mismatch",
+			macValues: append([]string{"tampered"}, testMACValues[1:]...),
+			key:       testKey,
+			wantErr:   ErrMACMismatch,
+		},
+		{
+			name:      "Wrong Key",
+			macValues: testMACValues,
+			key:       []byte(strings.Repeat("k", 32)),
+			wantErr:   ErrDecrypt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getSecret, err := Provider(writeTestFile(t, "secrets.yaml", tt.macValues...), StaticKey(tt.key))
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			_, err = getSecret(context.Background(), "password", secretly.DefaultVersion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProviderInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+
+	err := os.WriteFile(path, []byte("password: hunter2\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	_, err = Provider(path, StaticKey(testKey))
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrInvalidFile, err)
+	}
+}
+
+// TestProviderSOPSFixtures decrypts files encrypted by the sops binary (v3.9.0),
+// with the age identity in testdata/keys.txt, checking the MAC and additional data
+// are computed the same way as SOPS.
+func TestProviderSOPSFixtures(t *testing.T) {
+	t.Setenv(envAgeKeyFile, filepath.Join("testdata", "keys.txt"))
+
+	keySource, err := AgeKeySourceFromEnv()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		secretName string
+		want       string
+	}{
+		{
+			name:       "YAML Scalar",
+			file:       "secrets.yaml",
+			secretName: "password",
+			want:       "hunter2",
+		},
+		{
+			name:       "YAML Object",
+			file:       "secrets.yaml",
+			secretName: "db",
+			want:       "host: db.internal\nport: 5432\nratio: 1.5\ntls: true\nuser_unencrypted: admin\n",
+		},
+		{
+			name:       "YAML Array",
+			file:       "secrets.yaml",
+			secretName: "hosts",
+			want:       "- a.internal\n- b.internal\n",
+		},
+		{
+			name:       "JSON Scalar",
+			file:       "secrets.json",
+			secretName: "password",
+			want:       "hunter2",
+		},
+		{
+			name:       "JSON Object",
+			file:       "secrets.json",
+			secretName: "db",
+			want:       `{"host":"db.internal","port":5432,"ratio":1.5,"tls":true,"user_unencrypted":"admin"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getSecret, err := Provider(filepath.Join("testdata", tt.file), keySource)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			got, err := getSecret(context.Background(), tt.secretName, secretly.DefaultVersion)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderSOPSFixtureTampered(t *testing.T) {
+	t.Setenv(envAgeKeyFile, filepath.Join("testdata", "keys.txt"))
+
+	keySource, err := AgeKeySourceFromEnv()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "secrets.yaml"))
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	// Unencrypted values are covered by the MAC, so changing them is detected
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+
+	err = os.WriteFile(path, bytes.Replace(b, []byte("user_unencrypted: admin"), []byte("user_unencrypted: root"), 1), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	getSecret, err := Provider(path, keySource)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	_, err = getSecret(context.Background(), "password", secretly.DefaultVersion)
+	if !errors.Is(err, ErrMACMismatch) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrMACMismatch, err)
+	}
+}
@@ -0,0 +1,42 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// credentialsDirectoryEnv is the environment variable systemd sets to the directory
+// containing the service's credentials, passed with LoadCredential= and similar.
+const credentialsDirectoryEnv = "CREDENTIALS_DIRECTORY"
+
+var ErrCredentialsDirectoryNotSet = errors.New("$" + credentialsDirectoryEnv + " is not set")
+
+// SystemdCredentialsProvider returns a [GetSecretFunc] which reads secrets
+// from the systemd credentials directory, $CREDENTIALS_DIRECTORY.
+// Each secret name is the name of a credential, e.g. "db-password" for
+// LoadCredential=db-password:/etc/secrets/db-password.
+// Credentials are not versioned, so the version is ignored.
+//
+// If $CREDENTIALS_DIRECTORY is not set, [ErrCredentialsDirectoryNotSet] is returned.
+// Secret names containing path separators, or that are "." or "..",
+// are rejected with [ErrInvalidSecretName].
+func SystemdCredentialsProvider(opts ...FileOption) (GetSecretFunc, error) {
+	dir, ok := os.LookupEnv(credentialsDirectoryEnv)
+	if !ok || dir == "" {
+		return nil, ErrCredentialsDirectoryNotSet
+	}
+
+	getSecret := DirProvider(dir, opts...)
+
+	return func(ctx context.Context, name, version string) ([]byte, error) {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || filepath.IsAbs(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSecretName, name)
+		}
+
+		return getSecret(ctx, name, DefaultVersion)
+	}, nil
+}
@@ -0,0 +1,86 @@
+package secretly
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemdCredentialsProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "db-password"), []byte("password"), 0o600)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	t.Setenv(credentialsDirectoryEnv, dir)
+
+	getSecret, err := SystemdCredentialsProvider()
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "Credential",
+			secretName: "db-password",
+			version:    DefaultVersion,
+			want:       "password",
+		},
+		{
+			name:       "Version Ignored",
+			secretName: "db-password",
+			version:    "2",
+			want:       "password",
+		},
+		{
+			name:       "Not Found",
+			secretName: "api-key",
+			version:    DefaultVersion,
+			wantErr:    ErrSecretNotFound,
+		},
+		{
+			name:       "Path Traversal",
+			secretName: "../db-password",
+			version:    DefaultVersion,
+			wantErr:    ErrInvalidSecretName,
+		},
+		{
+			name:       "Parent Directory",
+			secretName: "..",
+			version:    DefaultVersion,
+			wantErr:    ErrInvalidSecretName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Incorrect secret. Want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSystemdCredentialsProviderNotSet(t *testing.T) {
+	t.Setenv(credentialsDirectoryEnv, "")
+
+	_, err := SystemdCredentialsProvider()
+	if !errors.Is(err, ErrCredentialsDirectoryNotSet) {
+		t.Fatalf("Incorrect error. Want %v, got %v", ErrCredentialsDirectoryNotSet, err)
+	}
+}
@@ -0,0 +1,367 @@
+// Package vault provides a [secretly.GetSecretFunc]
+// for HashiCorp Vault's KV secrets engine, versions 1 and 2.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+// Defaults for optional Config values.
+const (
+	DefaultMount        = "secret"
+	DefaultKVVersion    = 2
+	DefaultAppRoleMount = "approle"
+
+	// tokenExpiryMargin is subtracted from the AppRole token's lease duration
+	// so the token is renewed before it expires.
+	tokenExpiryMargin = 10 * time.Second
+)
+
+var (
+	ErrMissingAddress   = errors.New("vault: missing address")
+	ErrMissingAuth      = errors.New("vault: missing token or approle auth")
+	ErrInvalidKVVersion = errors.New("vault: invalid kv version")
+)
+
+type (
+	// Config configures the Vault provider.
+	Config struct {
+		// Address is Vault's address, e.g. "https://vault.example.com:8200".
+		Address string
+
+		// Mount is the path the KV secrets engine is mounted at.
+		// Defaults to [DefaultMount].
+		Mount string
+
+		// KVVersion is the version of the KV secrets engine, 1 or 2.
+		// Defaults to [DefaultKVVersion].
+		KVVersion int
+
+		// Namespace is the Vault Enterprise namespace, if any.
+		Namespace string
+
+		// Token authenticates with a Vault token.
+		// Either Token or AppRole must be set.
+		Token string
+
+		// AppRole authenticates with the AppRole auth method.
+		// Either Token or AppRole must be set.
+		AppRole *AppRole
+
+		// Client is the HTTP client used to make requests.
+		// Defaults to [http.DefaultClient].
+		Client *http.Client
+	}
+
+	// AppRole configures authentication with the AppRole auth method.
+	AppRole struct {
+		RoleID   string
+		SecretID string
+
+		// Mount is the path the AppRole auth method is mounted at.
+		// Defaults to [DefaultAppRoleMount].
+		Mount string
+	}
+
+	// provider gets secrets from Vault, authenticating as configured.
+	provider struct {
+		cfg Config
+
+		mu          sync.Mutex
+		token       string
+		tokenExpiry time.Time // Zero if the token does not expire.
+	}
+)
+
+// Provider returns a [secretly.GetSecretFunc] which gets secrets
+// from Vault's KV secrets engine.
+// Secret names are the path of the secret within the KV secrets engine's mount,
+// e.g. "app/db". The secret's data is returned as a JSON object,
+// so fields can be read with the "json" type and "key" tag.
+//
+// For KV version 2, versions are the secret's version numbers,
+// with [secretly.DefaultVersion] translating to the latest version.
+// KV version 1 is not versioned, so only [secretly.DefaultVersion] is accepted.
+//
+// Missing secrets, versions and deleted versions
+// are reported with errors wrapping [secretly.ErrSecretNotFound].
+// Other unexpected responses are reported with a [secretly.HTTPError].
+func Provider(cfg Config) (secretly.GetSecretFunc, error) {
+	if cfg.Address == "" {
+		return nil, ErrMissingAddress
+	}
+
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+
+	if cfg.Mount == "" {
+		cfg.Mount = DefaultMount
+	}
+
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = DefaultKVVersion
+	}
+
+	if cfg.KVVersion != 1 && cfg.KVVersion != 2 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidKVVersion, cfg.KVVersion)
+	}
+
+	if cfg.Token == "" && cfg.AppRole == nil {
+		return nil, ErrMissingAuth
+	}
+
+	if cfg.AppRole != nil && cfg.AppRole.Mount == "" {
+		cfg.AppRole.Mount = DefaultAppRoleMount
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	p := &provider{cfg: cfg, token: cfg.Token}
+
+	return p.getSecret, nil
+}
+
+// getSecret gets the secret's data, implementing [secretly.GetSecretFunc].
+func (p *provider) getSecret(ctx context.Context, name, version string) ([]byte, error) {
+	path, err := secretPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+
+	switch p.cfg.KVVersion {
+	case 1:
+		if version != secretly.DefaultVersion {
+			return nil, fmt.Errorf("%w: kv version 1 secrets are not versioned: %q", secretly.ErrInvalidSecretVersion, version)
+		}
+
+		path = p.cfg.Mount + "/" + path
+	case 2:
+		if version != secretly.DefaultVersion {
+			if _, err := strconv.ParseUint(version, 10, 64); err != nil {
+				return nil, fmt.Errorf("%w: %q", secretly.ErrInvalidSecretVersion, version)
+			}
+
+			query.Set("version", version)
+		}
+
+		path = p.cfg.Mount + "/data/" + path
+	}
+
+	b, err := p.doAuthenticated(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %q version %q: %w", name, version, err)
+	}
+
+	var data json.RawMessage
+
+	switch p.cfg.KVVersion {
+	case 1:
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+
+		err = json.Unmarshal(b, &resp)
+		data = resp.Data
+	case 2:
+		var resp struct {
+			Data struct {
+				Data json.RawMessage `json:"data"`
+			} `json:"data"`
+		}
+
+		err = json.Unmarshal(b, &resp)
+		data = resp.Data.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret %q version %q: %w", name, version, err)
+	}
+
+	if len(data) == 0 || string(data) == "null" {
+		return nil, fmt.Errorf("%w: secret %q version %q has no data", secretly.ErrSecretNotFound, name, version)
+	}
+
+	return data, nil
+}
+
+// doAuthenticated makes an authenticated request to Vault's API,
+// logging in with AppRole if configured and the token is missing or expired.
+// If an AppRole token is rejected, the request is retried once with a new token.
+func (p *provider) doAuthenticated(ctx context.Context, method, path string, query url.Values) ([]byte, error) {
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := p.do(ctx, method, path, query, token, nil)
+
+	var httpErr secretly.HTTPError
+	if p.cfg.AppRole != nil && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden {
+		p.resetToken(token)
+
+		token, err = p.getToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err = p.do(ctx, method, path, query, token, nil)
+	}
+
+	return b, err
+}
+
+// getToken returns the current token, logging in with AppRole
+// if configured and the token is missing or expired.
+func (p *provider) getToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.AppRole == nil {
+		return p.token, nil
+	}
+
+	if p.token != "" && (p.tokenExpiry.IsZero() || time.Now().Before(p.tokenExpiry)) {
+		return p.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"role_id":   p.cfg.AppRole.RoleID,
+		"secret_id": p.cfg.AppRole.SecretID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	b, err := p.do(ctx, http.MethodPost, "auth/"+strings.Trim(p.cfg.AppRole.Mount, "/")+"/login", nil, "", body)
+	if err != nil {
+		return "", fmt.Errorf("logging in with approle: %w", err)
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	err = json.Unmarshal(b, &resp)
+	if err != nil {
+		return "", fmt.Errorf("decoding approle login: %w", err)
+	}
+
+	p.token = resp.Auth.ClientToken
+	p.tokenExpiry = time.Time{}
+
+	if resp.Auth.LeaseDuration > 0 {
+		p.tokenExpiry = time.Now().Add(time.Duration(resp.Auth.LeaseDuration)*time.Second - tokenExpiryMargin)
+	}
+
+	return p.token, nil
+}
+
+// resetToken forgets the token, if it is still the current token,
+// so the next request logs in again.
+func (p *provider) resetToken(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == token {
+		p.token = ""
+	}
+}
+
+// do makes a request to Vault's API, returning the response body.
+// 404 responses are reported with an error wrapping [secretly.ErrSecretNotFound],
+// and other non-2xx responses with a [secretly.HTTPError].
+func (p *provider) do(ctx context.Context, method, path string, query url.Values, token string, body []byte) ([]byte, error) {
+	u := p.cfg.Address + "/v1/" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return b, nil
+	}
+
+	httpErr := secretly.HTTPError{StatusCode: resp.StatusCode, Message: errorMessage(b)}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", secretly.ErrSecretNotFound, httpErr)
+	}
+
+	return nil, httpErr
+}
+
+// errorMessage returns the errors reported in Vault's error response body, b.
+func errorMessage(b []byte) string {
+	var resp struct {
+		Errors []string `json:"errors"`
+	}
+
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return ""
+	}
+
+	return strings.Join(resp.Errors, "; ")
+}
+
+// secretPath returns the escaped path for the secret, name,
+// rejecting empty, "." and ".." path segments.
+func secretPath(name string) (string, error) {
+	segments := strings.Split(strings.Trim(name, "/"), "/")
+
+	for i, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %q", secretly.ErrInvalidSecretName, name)
+		}
+
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,312 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jack-mcveigh/secretly"
+)
+
+const (
+	testToken     = "test-token"
+	testNamespace = "team"
+)
+
+// newTestServer returns a stand-in for Vault serving KV version 1 secrets at "kv1"
+// and KV version 2 secrets at "secret", authenticating with testToken
+// or AppRole "role"/"secret". logins counts the AppRole logins.
+func newTestServer(t *testing.T, logins *int32) *httptest.Server {
+	t.Helper()
+
+	v2 := map[string]map[string]string{
+		"app/db": {
+			"1": `{"password": "password 1"}`,
+			"2": `{"password": "password 2"}`,
+		},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if req["role_id"] != "role" || req["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors": ["invalid role or secret ID"]}`))
+			return
+		}
+
+		atomic.AddInt32(logins, 1)
+		_, _ = w.Write([]byte(`{"auth": {"client_token": "` + testToken + `", "lease_duration": 3600}}`))
+	})
+
+	authenticated := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-Vault-Token") != testToken || r.Header.Get("X-Vault-Namespace") != testNamespace {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return false
+		}
+		return true
+	}
+
+	mux.HandleFunc("/v1/kv1/", func(w http.ResponseWriter, r *http.Request) {
+		if !authenticated(w, r) {
+			return
+		}
+
+		if strings.TrimPrefix(r.URL.Path, "/v1/kv1/") != "app/api" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data": {"key": "api key"}}`))
+	})
+
+	mux.HandleFunc("/v1/secret/data/", func(w http.ResponseWriter, r *http.Request) {
+		if !authenticated(w, r) {
+			return
+		}
+
+		versions, ok := v2[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+			return
+		}
+
+		version := r.URL.Query().Get("version")
+		if version == "" {
+			version = "2"
+		}
+
+		data, ok := versions[version]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"data": {"data": null, "metadata": {}}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data": {"data": ` + data + `, "metadata": {"version": ` + version + `}}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProvider(t *testing.T) {
+	var logins int32
+
+	server := newTestServer(t, &logins)
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		secretName string
+		version    string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "KV2 Latest",
+			cfg:        Config{Token: testToken},
+			secretName: "app/db",
+			version:    secretly.DefaultVersion,
+			want:       `{"password": "password 2"}`,
+		},
+		{
+			name:       "KV2 Version",
+			cfg:        Config{Token: testToken},
+			secretName: "app/db",
+			version:    "1",
+			want:       `{"password": "password 1"}`,
+		},
+		{
+			name:       "KV2 Missing Version",
+			cfg:        Config{Token: testToken},
+			secretName: "app/db",
+			version:    "3",
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "KV2 Invalid Version",
+			cfg:        Config{Token: testToken},
+			secretName: "app/db",
+			version:    "latest",
+			wantErr:    secretly.ErrInvalidSecretVersion,
+		},
+		{
+			name:       "KV2 Missing Secret",
+			cfg:        Config{Token: testToken},
+			secretName: "app/other",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrSecretNotFound,
+		},
+		{
+			name:       "KV1",
+			cfg:        Config{Token: testToken, Mount: "kv1", KVVersion: 1},
+			secretName: "app/api",
+			version:    secretly.DefaultVersion,
+			want:       `{"key": "api key"}`,
+		},
+		{
+			name:       "KV1 Version",
+			cfg:        Config{Token: testToken, Mount: "kv1", KVVersion: 1},
+			secretName: "app/api",
+			version:    "1",
+			wantErr:    secretly.ErrInvalidSecretVersion,
+		},
+		{
+			name:       "AppRole",
+			cfg:        Config{AppRole: &AppRole{RoleID: "role", SecretID: "secret"}},
+			secretName: "app/db",
+			version:    secretly.DefaultVersion,
+			want:       `{"password": "password 2"}`,
+		},
+		{
+			name:       "Invalid Path",
+			cfg:        Config{Token: testToken},
+			secretName: "app/../sys",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.ErrInvalidSecretName,
+		},
+		{
+			name:       "Permission Denied",
+			cfg:        Config{Token: "invalid"},
+			secretName: "app/db",
+			version:    secretly.DefaultVersion,
+			wantErr:    secretly.HTTPError{StatusCode: http.StatusForbidden, Message: "permission denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Address = server.URL
+			tt.cfg.Namespace = testNamespace
+
+			getSecret, err := Provider(tt.cfg)
+			if err != nil {
+				t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+			}
+
+			got, err := getSecret(context.Background(), tt.secretName, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr == nil && !jsonEqual(t, tt.want, string(got)) {
+				t.Errorf("Incorrect secret. Want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderAppRoleLogin(t *testing.T) {
+	var logins int32
+
+	server := newTestServer(t, &logins)
+
+	getSecret, err := Provider(Config{
+		Address:   server.URL,
+		Namespace: testNamespace,
+		AppRole:   &AppRole{RoleID: "role", SecretID: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := getSecret(context.Background(), "app/db", secretly.DefaultVersion)
+		if err != nil {
+			t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+		}
+	}
+
+	if logins != 1 {
+		t.Errorf("Incorrect number of logins. Want %d, got %d", 1, logins)
+	}
+}
+
+func TestProviderProcess(t *testing.T) {
+	var logins int32
+
+	server := newTestServer(t, &logins)
+
+	getSecret, err := Provider(Config{Address: server.URL, Namespace: testNamespace, Token: testToken})
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	var spec struct {
+		Password string `type:"json" name:"app/db" key:"password" version:"1"`
+	}
+
+	err = secretly.Process(context.Background(), &spec, getSecret)
+	if err != nil {
+		t.Fatalf("Incorrect error. Want %v, got %v", nil, err)
+	}
+
+	if spec.Password != "password 1" {
+		t.Errorf("Incorrect password. Want %q, got %q", "password 1", spec.Password)
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "Missing Address",
+			cfg:     Config{Token: testToken},
+			wantErr: ErrMissingAddress,
+		},
+		{
+			name:    "Missing Auth",
+			cfg:     Config{Address: "http://localhost:8200"},
+			wantErr: ErrMissingAuth,
+		},
+		{
+			name:    "Invalid KV Version",
+			cfg:     Config{Address: "http://localhost:8200", Token: testToken, KVVersion: 3},
+			wantErr: ErrInvalidKVVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Provider(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Incorrect error. Want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// jsonEqual reports whether the JSON documents, a and b, are equal.
+func jsonEqual(t *testing.T, a, b string) bool {
+	t.Helper()
+
+	var va, vb any
+
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", a, err)
+	}
+
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}